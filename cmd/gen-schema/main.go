@@ -0,0 +1,203 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// gen-schema writes a JSON Schema (draft-07) for the picoclaw config file,
+// for editor autocompletion and validation.
+//
+//	go run ./cmd/gen-schema -o config/config.schema.json
+//
+// Property names come from the json struct tags. An optional schema tag adds
+// constraints, as semicolon-separated entries:
+//
+//	schema:"required;description=Gateway port;minimum=1;maximum=65535;enum=a|b"
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+var (
+	flexibleStringSliceType = reflect.TypeFor[config.FlexibleStringSlice]()
+	flexibleIntOrBoolType   = reflect.TypeFor[config.FlexibleIntOrBool]()
+	agentModelConfigType    = reflect.TypeFor[config.AgentModelConfig]()
+)
+
+func main() {
+	output := flag.String("o", "", "write the schema to this file instead of stdout")
+	flag.Parse()
+
+	data, err := json.MarshalIndent(generateSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generateSchema builds the schema for config.Config.
+func generateSchema() *jsonschema.Schema {
+	s := schemaForType(reflect.TypeFor[config.Config]())
+	s.Schema = draft07
+	s.Title = "PicoClaw configuration"
+	return s
+}
+
+func schemaForType(t reflect.Type) *jsonschema.Schema {
+	switch t {
+	case flexibleStringSliceType:
+		// Entries may be written as strings or numbers, e.g. Telegram user IDs,
+		// and a single entry may be given without the list.
+		return &jsonschema.Schema{AnyOf: []*jsonschema.Schema{
+			{
+				Types: []string{"array", "null"},
+				Items: &jsonschema.Schema{Types: []string{"string", "number"}},
+			},
+			{Types: []string{"string", "number"}},
+		}}
+	case flexibleIntOrBoolType:
+		return &jsonschema.Schema{AnyOf: []*jsonschema.Schema{
+			{Types: []string{"integer", "boolean"}},
+			{Type: "string", Pattern: `^\s*-?[0-9]+\s*$`},
+		}}
+	case agentModelConfigType:
+		// Either "gpt-4" or {"primary": "gpt-4", "fallbacks": [...]}.
+		return &jsonschema.Schema{AnyOf: []*jsonschema.Schema{
+			{Type: "string"},
+			structSchema(t),
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(schemaForType(t.Elem()))
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		// Nil slices and maps are saved as null.
+		return nullable(&jsonschema.Schema{Type: "array", Items: schemaForType(t.Elem())})
+	case reflect.Map:
+		return nullable(&jsonschema.Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem())})
+	case reflect.String:
+		return &jsonschema.Schema{Type: "string"}
+	case reflect.Bool:
+		return &jsonschema.Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonschema.Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonschema.Schema{Type: "number"}
+	default:
+		return &jsonschema.Schema{}
+	}
+}
+
+// nullable widens s to also accept null.
+func nullable(s *jsonschema.Schema) *jsonschema.Schema {
+	if s.Type != "" {
+		s.Types = []string{s.Type, "null"}
+		s.Type = ""
+	}
+	return s
+}
+
+// structSchema describes a struct as a closed object, so misspelled keys are
+// reported instead of being silently ignored by the loader. Keys starting
+// with "_" are left open for comments, as used in config.example.json.
+func structSchema(t reflect.Type) *jsonschema.Schema {
+	s := &jsonschema.Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonschema.Schema),
+		PatternProperties:    map[string]*jsonschema.Schema{"^_": {}},
+		AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+	}
+	addFields(s, t)
+	return s
+}
+
+func addFields(s *jsonschema.Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			// Embedded structs are flattened by encoding/json.
+			addFields(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaForType(f.Type)
+		if applySchemaTag(prop, f.Tag.Get("schema")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+		s.PropertyOrder = append(s.PropertyOrder, name)
+	}
+}
+
+// applySchemaTag copies the constraints in tag onto s and reports whether the
+// field is marked required.
+func applySchemaTag(s *jsonschema.Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for _, entry := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(entry, "=")
+		switch key {
+		case "required":
+			required = true
+		case "description":
+			s.Description = value
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				s.Enum = append(s.Enum, v)
+			}
+		case "minimum":
+			s.Minimum = parseBound(value)
+		case "maximum":
+			s.Maximum = parseBound(value)
+		default:
+			panic(fmt.Sprintf("unknown schema tag entry %q", entry))
+		}
+	}
+	return required
+}
+
+func parseBound(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("invalid schema bound %q", value))
+	}
+	return &f
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// resolveSchema round-trips the generated schema through JSON, as an editor
+// would see it, and prepares it for validation.
+func resolveSchema(t *testing.T) *jsonschema.Resolved {
+	t.Helper()
+
+	data, err := json.Marshal(generateSchema())
+	require.NoError(t, err)
+
+	var s jsonschema.Schema
+	require.NoError(t, json.Unmarshal(data, &s))
+	assert.Equal(t, draft07, s.Schema)
+
+	resolved, err := s.Resolve(nil)
+	require.NoError(t, err)
+	return resolved
+}
+
+func decodeInstance(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var v map[string]any
+	require.NoError(t, json.Unmarshal(data, &v))
+	return v
+}
+
+func TestSchemaAcceptsExampleConfig(t *testing.T) {
+	resolved := resolveSchema(t)
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "config.example.json"))
+	require.NoError(t, err)
+	assert.NoError(t, resolved.Validate(decodeInstance(t, data)))
+}
+
+func TestSchemaAcceptsSavedDefaultConfig(t *testing.T) {
+	resolved := resolveSchema(t)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, config.SaveConfig(path, config.DefaultConfig()))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NoError(t, resolved.Validate(decodeInstance(t, data)))
+}
+
+func TestSchemaFlexibleValues(t *testing.T) {
+	resolved := resolveSchema(t)
+
+	cfg := `{
+		"agents": {"list": [{"id": "a", "model": "gpt-4o"}, {"id": "b", "model": {"primary": "gpt-4o", "fallbacks": ["x"]}}]},
+		"channels": {"telegram": {"allow_from": ["alice", 123456]}, "discord": {"allow_from": "bob"}},
+		"heartbeat": {"interval": "30"},
+		"tools": {"cron": {"exec_timeout_minutes": false}}
+	}`
+	assert.NoError(t, resolved.Validate(decodeInstance(t, []byte(cfg))))
+}
+
+func TestSchemaRejectsBadConfig(t *testing.T) {
+	resolved := resolveSchema(t)
+
+	tests := map[string]string{
+		"wrong type":       `{"gateway": {"port": "18790"}}`,
+		"out of range":     `{"gateway": {"port": 70000}}`,
+		"below minimum":    `{"heartbeat": {"interval": 1}}`,
+		"unknown key":      `{"channels": {"telegram": {"tokn": "x"}}}`,
+		"bad enum":         `{"session": {"dm_scope": "everyone"}}`,
+		"missing required": `{"model_list": [{"model_name": "gpt"}]}`,
+		"bad allow_from":   `{"channels": {"telegram": {"allow_from": [true]}}}`,
+		"non-numeric int":  `{"heartbeat": {"interval": "soon"}}`,
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, resolved.Validate(decodeInstance(t, []byte(cfg))))
+		})
+	}
+}
+
+func TestApplySchemaTag(t *testing.T) {
+	var s jsonschema.Schema
+	required := applySchemaTag(&s, "required;description=Port;minimum=1;maximum=10;enum=a|b")
+
+	assert.True(t, required)
+	assert.Equal(t, "Port", s.Description)
+	assert.Equal(t, []any{"a", "b"}, s.Enum)
+	require.NotNil(t, s.Minimum)
+	require.NotNil(t, s.Maximum)
+	assert.InDelta(t, 1, *s.Minimum, 0)
+	assert.InDelta(t, 10, *s.Maximum, 0)
+
+	assert.Panics(t, func() { applySchemaTag(&s, "minimun=1") })
+}
+
+func TestCommittedSchemaIsCurrent(t *testing.T) {
+	want, err := json.MarshalIndent(generateSchema(), "", "  ")
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(filepath.Join("..", "..", "config", "config.schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want)+"\n", string(got), "run `make schema` to regenerate config/config.schema.json")
+}
@@ -15,9 +15,9 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
-const supportedProvidersMsg = "supported providers: openai, anthropic, google-antigravity"
+const supportedProvidersMsg = "supported providers: openai, anthropic, google-antigravity, youtube"
 
-func authLoginCmd(provider string, useDeviceCode bool) error {
+func authLoginCmd(provider string, useDeviceCode bool, clientID string) error {
 	switch provider {
 	case "openai":
 		return authLoginOpenAI(useDeviceCode)
@@ -25,6 +25,8 @@ func authLoginCmd(provider string, useDeviceCode bool) error {
 		return authLoginPasteToken(provider)
 	case "google-antigravity", "antigravity":
 		return authLoginGoogleAntigravity()
+	case "youtube":
+		return authLoginYouTube(clientID)
 	default:
 		return fmt.Errorf("unsupported provider: %s (%s)", provider, supportedProvidersMsg)
 	}
@@ -163,6 +165,32 @@ func authLoginGoogleAntigravity() error {
 	return nil
 }
 
+func authLoginYouTube(clientID string) error {
+	if clientID == "" {
+		return fmt.Errorf("youtube login requires --client-id from your Google Cloud OAuth client")
+	}
+
+	cred, err := auth.LoginBrowser(auth.YouTubeOAuthConfig(clientID))
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	cred.Provider = "youtube"
+
+	if err = auth.SetCredential("youtube", cred); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	tokenPath := auth.YouTubeTokenPath()
+	if err = auth.WriteTokenFile(tokenPath, cred); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+
+	fmt.Println("\n✓ YouTube login successful!")
+	fmt.Printf("Token written to: %s\n", tokenPath)
+
+	return nil
+}
+
 func fetchGoogleUserEmail(accessToken string) (string, error) {
 	req, err := http.NewRequest("GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
 	if err != nil {
@@ -48,7 +48,17 @@ func gatewayCmd(debug bool) error {
 		cfg.Agents.Defaults.ModelName = modelID
 	}
 
-	msgBus := bus.NewMessageBus()
+	msgBus, memBus, err := newMessageBus(cfg.Bus)
+	if err != nil {
+		return err
+	}
+	defer msgBus.Close()
+	if overrides := cfg.Routing.SystemPromptOverrides; len(overrides) > 0 {
+		if memBus == nil {
+			return fmt.Errorf("routing.system_prompt_overrides is not supported with bus.nats_url")
+		}
+		memBus.UseMiddleware(bus.SystemPromptOverrides(overrides))
+	}
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
 
 	// Print agent startup info
@@ -82,7 +92,7 @@ func gatewayCmd(debug bool) error {
 
 	heartbeatService := heartbeat.NewHeartbeatService(
 		cfg.WorkspacePath(),
-		cfg.Heartbeat.Interval,
+		int(cfg.Heartbeat.Interval),
 		cfg.Heartbeat.Enabled,
 	)
 	heartbeatService.SetBus(msgBus)
@@ -196,7 +206,18 @@ func gatewayCmd(debug bool) error {
 	}()
 	fmt.Printf("✓ Health endpoints available at http://%s:%d/health and /ready\n", cfg.Gateway.Host, cfg.Gateway.Port)
 
-	go agentLoop.Run(ctx)
+	// An external process attached to the bus socket answers inbound messages
+	// in place of the built-in agent.
+	var busServer *bus.UnixServer
+	if path := cfg.Bus.UnixSocketPath; path != "" {
+		busServer, err = memBus.ListenUnix(path)
+		if err != nil {
+			return fmt.Errorf("error starting bus socket: %w", err)
+		}
+		fmt.Printf("✓ Message bus available on unix socket %s (built-in agent disabled)\n", path)
+	} else {
+		go agentLoop.Run(ctx)
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
@@ -212,15 +233,37 @@ func gatewayCmd(debug bool) error {
 	heartbeatService.Stop()
 	cronService.Stop()
 	agentLoop.Stop()
+	if busServer != nil {
+		busServer.Close()
+	}
 	channelManager.StopAll(ctx)
 	fmt.Println("✓ Gateway stopped")
 
 	return nil
 }
 
+// newMessageBus returns the bus selected by cfg. mem is the in-process bus
+// when that is the one in use, and nil for NATS.
+func newMessageBus(cfg config.BusServerConfig) (b bus.Bus, mem *bus.MessageBus, err error) {
+	if cfg.NATSURL == "" {
+		mem = bus.NewMessageBus()
+		mem.SetDeadLetterFile(cfg.DeadLetterFile)
+		return mem, mem, nil
+	}
+	if cfg.UnixSocketPath != "" {
+		return nil, nil, fmt.Errorf("bus.unix_socket_path cannot be combined with bus.nats_url")
+	}
+	natsBus, err := bus.NewNATSBus(cfg.NATSURL, cfg.NATSStream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to NATS bus: %w", err)
+	}
+	fmt.Printf("✓ Message bus on NATS %s\n", cfg.NATSURL)
+	return natsBus, nil, nil
+}
+
 func setupCronTool(
 	agentLoop *agent.AgentLoop,
-	msgBus *bus.MessageBus,
+	msgBus bus.Bus,
 	workspace string,
 	restrict bool,
 	execTimeout time.Duration,
@@ -18,9 +18,26 @@ var (
 	goVersion string
 )
 
+// configFileNames lists the config files looked up in ~/.picoclaw, in order
+// of preference. config.json stays the default for new installs.
+var configFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// GetConfigPath returns $PICOCLAW_CONFIG when set, otherwise the first
+// existing file from configFileNames in ~/.picoclaw, falling back to
+// ~/.picoclaw/config.json.
 func GetConfigPath() string {
+	if path := os.Getenv("PICOCLAW_CONFIG"); path != "" {
+		return path
+	}
 	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".picoclaw", "config.json")
+	dir := filepath.Join(home, ".picoclaw")
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, configFileNames[0])
 }
 
 func LoadConfig() (*config.Config, error) {
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 func TestGetConfigPath(t *testing.T) {
 	t.Setenv("HOME", "/tmp/home")
+	t.Setenv("PICOCLAW_CONFIG", "")
 
 	got := GetConfigPath()
 	want := filepath.Join("/tmp/home", ".picoclaw", "config.json")
@@ -19,6 +21,27 @@ func TestGetConfigPath(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestGetConfigPath_FallsBackToOtherFormats(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PICOCLAW_CONFIG", "")
+	dir := filepath.Join(home, ".picoclaw")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	for _, name := range []string{"config.toml", "config.yml", "config.yaml", "config.json"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte{}, 0o600))
+		assert.Equal(t, path, GetConfigPath(), "with %s present", name)
+	}
+}
+
+func TestGetConfigPath_EnvOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PICOCLAW_CONFIG", "/etc/picoclaw/config.yaml")
+
+	assert.Equal(t, "/etc/picoclaw/config.yaml", GetConfigPath())
+}
+
 func TestFormatVersion_NoGitCommit(t *testing.T) {
 	oldVersion, oldGit := version, gitCommit
 	t.Cleanup(func() { version, gitCommit = oldVersion, oldGit })
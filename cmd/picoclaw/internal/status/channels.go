@@ -0,0 +1,159 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// channelTypes describes how each channel talks to its platform.
+var channelTypes = map[string]string{
+	"whatsapp":           "bridge",
+	"telegram":           "long polling",
+	"feishu":             "websocket",
+	"discord":            "gateway",
+	"maixcam":            "tcp server",
+	"qq":                 "websocket",
+	"dingtalk":           "stream",
+	"slack":              "socket mode / events api",
+	"line":               "webhook",
+	"onebot":             "websocket",
+	"wecom":              "webhook",
+	"wecom_app":          "webhook",
+	"twitch_eventsub":    "websocket",
+	"twicas":             "polling",
+	"niconico_timeshift": "replay",
+	"linelive":           "websocket",
+	"notion":             "rest (outbound)",
+}
+
+// channelRequirements lists the settings each channel needs before it can
+// start, mirroring the checks in pkg/channels. "a|b" means either is enough.
+var channelRequirements = map[string][]string{
+	"whatsapp":           {"bridge_url"},
+	"telegram":           {"token"},
+	"feishu":             {"app_id", "app_secret"},
+	"discord":            {"token"},
+	"qq":                 {"app_id", "app_secret"},
+	"dingtalk":           {"client_id", "client_secret"},
+	"slack":              {"bot_token", "app_token|signing_secret"},
+	"line":               {"channel_secret", "channel_access_token"},
+	"onebot":             {"ws_url"},
+	"wecom":              {"token", "webhook_url"},
+	"wecom_app":          {"corp_id", "corp_secret", "agent_id"},
+	"twitch_eventsub":    {"client_id", "user_access_token", "broadcaster_user_id"},
+	"twicas":             {"movie_id", "access_token"},
+	"niconico_timeshift": {"content_id", "user_session"},
+	"linelive":           {"broadcast_id", "access_token"},
+	"notion":             {"integration_token", "database_id"},
+}
+
+// ListChannels prints every channel in cfg with its settings and whether it
+// would pass validation. It only inspects the config; nothing is started.
+func ListChannels(w io.Writer, cfg *config.Config) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANNEL\tTYPE\tENABLED\tVALID\tSETTINGS")
+
+	channels := reflect.ValueOf(cfg.Channels)
+	for i := range channels.NumField() {
+		name := jsonName(channels.Type().Field(i))
+		fields := channelFields(channels.Field(i))
+
+		enabled := "no"
+		if fields["enabled"].IsValid() && fields["enabled"].Bool() {
+			enabled = "yes"
+		}
+
+		typ := channelTypes[name]
+		if typ == "" {
+			typ = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			name, typ, enabled, validateChannel(name, fields), describeSettings(channels.Field(i)))
+	}
+	tw.Flush()
+}
+
+// channelFields indexes a channel config struct by JSON field name.
+func channelFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value, v.NumField())
+	for i := range v.NumField() {
+		fields[jsonName(v.Type().Field(i))] = v.Field(i)
+	}
+	return fields
+}
+
+func validateChannel(name string, fields map[string]reflect.Value) string {
+	var missing []string
+	for _, req := range channelRequirements[name] {
+		satisfied := false
+		for _, alt := range strings.Split(req, "|") {
+			if f, ok := fields[alt]; ok && !f.IsZero() {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			missing = append(missing, strings.ReplaceAll(req, "|", " or "))
+		}
+	}
+	if len(missing) > 0 {
+		return "missing " + strings.Join(missing, ", ")
+	}
+	return "ok"
+}
+
+// describeSettings renders the non-empty scalar settings of a channel,
+// masking anything that looks like a credential.
+func describeSettings(v reflect.Value) string {
+	var parts []string
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name := jsonName(field)
+		f := v.Field(i)
+		if name == "enabled" || f.IsZero() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Bool:
+		default:
+			continue
+		}
+		val := fmt.Sprint(f.Interface())
+		if isSecretSetting(field, name) {
+			val = "****"
+		}
+		parts = append(parts, name+"="+val)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+// isSecretSetting reports whether a field is tagged config:"secret", or,
+// failing that, whether its name looks like a credential.
+func isSecretSetting(f reflect.StructField, name string) bool {
+	if f.Tag.Get("config") == "secret" {
+		return true
+	}
+	for _, marker := range []string{"token", "secret", "key", "password"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
@@ -33,6 +33,20 @@ func NewPicoclawCommand() *cobra.Command {
 		Example: "picoclaw list",
 	}
 
+	var listChannels bool
+	cmd.Flags().BoolVar(&listChannels, "list-channels", false, "Print configured channels and their status, then exit")
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		if !listChannels {
+			return cmd.Help()
+		}
+		cfg, err := internal.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("error loading config: %w", err)
+		}
+		status.ListChannels(cmd.OutOrStdout(), cfg)
+		return nil
+	}
+
 	cmd.AddCommand(
 		onboard.NewOnboardCommand(),
 		agent.NewAgentCommand(),
@@ -24,10 +24,11 @@ func TestNewPicoclawCommand(t *testing.T) {
 	assert.True(t, cmd.HasSubCommands())
 	assert.True(t, cmd.HasAvailableSubCommands())
 
-	assert.False(t, cmd.HasFlags())
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("list-channels"))
 
 	assert.Nil(t, cmd.Run)
-	assert.Nil(t, cmd.RunE)
+	assert.NotNil(t, cmd.RunE)
 
 	assert.Nil(t, cmd.PersistentPreRun)
 	assert.Nil(t, cmd.PersistentPostRun)
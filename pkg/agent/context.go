@@ -381,6 +381,21 @@ func (cb *ContextBuilder) BuildMessages(
 	currentMessage string,
 	media []string,
 	channel, chatID string,
+) []providers.Message {
+	return cb.BuildMessagesWithSystemPrompt("", history, summary, currentMessage, media, channel, chatID)
+}
+
+// BuildMessagesWithSystemPrompt is BuildMessages with systemPrompt used in
+// place of the static (identity, bootstrap, skills, memory) prompt. An empty
+// systemPrompt keeps the static prompt. The dynamic context and summary are
+// appended either way.
+func (cb *ContextBuilder) BuildMessagesWithSystemPrompt(
+	systemPrompt string,
+	history []providers.Message,
+	summary string,
+	currentMessage string,
+	media []string,
+	channel, chatID string,
 ) []providers.Message {
 	messages := []providers.Message{}
 
@@ -393,7 +408,10 @@ func (cb *ContextBuilder) BuildMessages(
 	//   contiguous system block makes this extraction straightforward.
 	// - Codex maps only the first system message to its instructions field.
 	// - OpenAI-compat passes messages through as-is.
-	staticPrompt := cb.BuildSystemPromptWithCache()
+	staticPrompt := systemPrompt
+	if staticPrompt == "" {
+		staticPrompt = cb.BuildSystemPromptWithCache()
+	}
 
 	// Build short dynamic context (time, runtime, session) — changes per request
 	dynamicCtx := cb.buildDynamicContext(channel, chatID)
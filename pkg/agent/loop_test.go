@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -631,3 +633,207 @@ func TestAgentLoop_ContextExhaustionRetry(t *testing.T) {
 		t.Errorf("Expected history to be compressed (len < 8), got %d", len(finalHistory))
 	}
 }
+
+// TestAgentLoop_PropagatesTraceID verifies a reply carries the trace ID of the
+// inbound message that caused it.
+func TestAgentLoop_PropagatesTraceID(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &simpleMockProvider{response: "pong"})
+
+	ctx, cancel := context.WithTimeout(t.Context(), responseTimeout)
+	defer cancel()
+	go al.Run(ctx)
+	defer al.Stop()
+
+	traceID := bus.NewTraceID()
+	err := msgBus.PublishInbound(ctx, bus.InboundMessage{
+		Channel:    "telegram",
+		SenderID:   "user1",
+		ChatID:     "chat1",
+		Content:    "ping",
+		SessionKey: "test-session",
+		TraceID:    traceID,
+	})
+	if err != nil {
+		t.Fatalf("PublishInbound: %v", err)
+	}
+
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("no outbound message before timeout")
+	}
+	if out.Content != "pong" || out.ChatID != "chat1" {
+		t.Errorf("outbound = %+v", out)
+	}
+	if out.TraceID != traceID {
+		t.Errorf("outbound TraceID = %q, want %q", out.TraceID, traceID)
+	}
+}
+
+// promptRecordingProvider records the system prompt of every request.
+type promptRecordingProvider struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (m *promptRecordingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(messages) > 0 && messages[0].Role == "system" {
+		m.prompts = append(m.prompts, messages[0].Content)
+	}
+	return &providers.LLMResponse{Content: "ok"}, nil
+}
+
+func (m *promptRecordingProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestAgentLoop_UsesSystemPromptOverride(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "IDENTITY.md"), []byte("STATIC IDENTITY"), 0o644)
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	msgBus.UseMiddleware(bus.SystemPromptOverrides(map[string]string{
+		"superchat": "Thank the viewer for the superchat.",
+	}))
+	provider := &promptRecordingProvider{}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	ctx, cancel := context.WithTimeout(t.Context(), responseTimeout)
+	defer cancel()
+	go al.Run(ctx)
+	defer al.Stop()
+
+	for _, event := range []string{"superchat", "chat"} {
+		err := msgBus.PublishInbound(ctx, bus.InboundMessage{
+			Channel:  "youtube",
+			SenderID: "viewer",
+			ChatID:   "live",
+			Content:  "hello",
+			Metadata: map[string]string{"event": event},
+		})
+		if err != nil {
+			t.Fatalf("PublishInbound: %v", err)
+		}
+		if _, ok := msgBus.SubscribeOutbound(ctx); !ok {
+			t.Fatalf("no response to %s event before timeout", event)
+		}
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if len(provider.prompts) != 2 {
+		t.Fatalf("got %d LLM calls, want 2", len(provider.prompts))
+	}
+	if p := provider.prompts[0]; !strings.HasPrefix(p, "Thank the viewer for the superchat.") ||
+		strings.Contains(p, "STATIC IDENTITY") {
+		t.Errorf("superchat system prompt = %q, want the override", p)
+	}
+	if p := provider.prompts[1]; !strings.Contains(p, "STATIC IDENTITY") || strings.Contains(p, "superchat") {
+		t.Errorf("chat system prompt = %q, want the static prompt", p)
+	}
+}
+
+// messageToolProvider calls the message tool once, then answers with "done".
+type messageToolProvider struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *messageToolProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.calls == 1 {
+		return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{
+			ID:        "call_1",
+			Type:      "function",
+			Name:      "message",
+			Arguments: map[string]any{"content": "via tool"},
+		}}}, nil
+	}
+	return &providers.LLMResponse{Content: "done"}, nil
+}
+
+func (m *messageToolProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestAgentLoop_MessageToolPropagatesTraceID(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, &messageToolProvider{})
+
+	ctx, cancel := context.WithTimeout(t.Context(), responseTimeout)
+	defer cancel()
+	go al.Run(ctx)
+	defer al.Stop()
+
+	traceID := bus.NewTraceID()
+	err := msgBus.PublishInbound(ctx, bus.InboundMessage{
+		Channel:    "telegram",
+		SenderID:   "user1",
+		ChatID:     "chat1",
+		Content:    "ping",
+		SessionKey: "test-session",
+		TraceID:    traceID,
+	})
+	if err != nil {
+		t.Fatalf("PublishInbound: %v", err)
+	}
+
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("no outbound message before timeout")
+	}
+	if out.Content != "via tool" {
+		t.Fatalf("outbound = %+v, want the message tool's message", out)
+	}
+	if out.TraceID != traceID {
+		t.Errorf("message tool TraceID = %q, want %q", out.TraceID, traceID)
+	}
+}
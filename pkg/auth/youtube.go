@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/oauth2"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// YouTubeOAuthConfig returns the OAuth configuration for the YouTube Data API.
+// There is no shared client for YouTube, so clientID must come from the
+// user's own Google Cloud project. The flow relies on PKCE and sends no
+// client secret, which suits public (installed-app) clients.
+func YouTubeOAuthConfig(clientID string) OAuthProviderConfig {
+	return OAuthProviderConfig{
+		Issuer:   "https://accounts.google.com/o/oauth2/v2",
+		TokenURL: "https://oauth2.googleapis.com/token",
+		ClientID: clientID,
+		Scopes:   "https://www.googleapis.com/auth/youtube.readonly",
+		Port:     8085,
+	}
+}
+
+// YouTubeTokenPath is where the YouTube login exports its token.
+func YouTubeTokenPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".picoclaw", "youtube_token.json")
+}
+
+// WriteTokenFile writes cred to path in the golang.org/x/oauth2 Token JSON
+// format, so it can be loaded by any oauth2-based client.
+func WriteTokenFile(path string, cred *AuthCredential) error {
+	token := oauth2.Token{
+		AccessToken:  cred.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: cred.RefreshToken,
+		Expiry:       cred.ExpiresAt,
+	}
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fileutil.WriteFileAtomic(path, data, 0o600)
+}
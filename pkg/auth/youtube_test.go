@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestYouTubeOAuthConfig(t *testing.T) {
+	cfg := YouTubeOAuthConfig("my-client")
+	if cfg.ClientID != "my-client" {
+		t.Errorf("ClientID = %q, want %q", cfg.ClientID, "my-client")
+	}
+	if cfg.ClientSecret != "" {
+		t.Error("YouTube config should not carry a client secret")
+	}
+	if cfg.Port != 8085 {
+		t.Errorf("Port = %d, want 8085", cfg.Port)
+	}
+
+	redirectURI := "http://localhost:8085/auth/callback"
+	authURL := buildAuthorizeURL(cfg, PKCECodes{CodeChallenge: "challenge"}, "state", redirectURI)
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge") != "challenge" || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("authorize URL missing PKCE parameters: %s", u)
+	}
+	if q.Get("access_type") != "offline" {
+		t.Errorf("access_type = %q, want offline", q.Get("access_type"))
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	resultCh := make(chan callbackResult, 1)
+	srv := httptest.NewServer(callbackHandler("good-state", resultCh))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?state=bad-state&code=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status for wrong state = %d, want 400", resp.StatusCode)
+	}
+	if res := <-resultCh; res.err == nil {
+		t.Error("wrong state should report an error")
+	}
+
+	resp, err = http.Get(srv.URL + "?state=good-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if res := <-resultCh; res.err == nil {
+		t.Error("missing code should report an error")
+	}
+}
+
+func TestYouTubeLoginWritesTokenFile(t *testing.T) {
+	pkce, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.FormValue("code") != "auth-code" || r.FormValue("code_verifier") != pkce.CodeVerifier {
+			http.Error(w, "bad code or verifier", http.StatusBadRequest)
+			return
+		}
+		if r.Form.Has("client_secret") {
+			http.Error(w, "public client must not send a secret", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "ya29.access",
+			"refresh_token": "1//refresh",
+			"expires_in":    3599,
+			"token_type":    "Bearer",
+		})
+	}))
+	defer tokenSrv.Close()
+
+	cfg := YouTubeOAuthConfig("my-client")
+	cfg.TokenURL = tokenSrv.URL
+
+	// Simulate Google redirecting the browser back to the local callback.
+	resultCh := make(chan callbackResult, 1)
+	callbackSrv := httptest.NewServer(callbackHandler("xyz", resultCh))
+	defer callbackSrv.Close()
+	resp, err := http.Get(callbackSrv.URL + "/auth/callback?state=xyz&code=auth-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("callback status = %d, want 200", resp.StatusCode)
+	}
+	res := <-resultCh
+	if res.err != nil {
+		t.Fatalf("callback error: %v", res.err)
+	}
+
+	cred, err := exchangeCodeForTokens(cfg, res.code, pkce.CodeVerifier, callbackSrv.URL+"/auth/callback")
+	if err != nil {
+		t.Fatalf("exchangeCodeForTokens: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "youtube_token.json")
+	if err := WriteTokenFile(path, cred); err != nil {
+		t.Fatalf("WriteTokenFile: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("token file mode = %o, want 600", perm)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		t.Fatalf("token file is not oauth2.Token JSON: %v", err)
+	}
+	if token.AccessToken != "ya29.access" || token.RefreshToken != "1//refresh" || token.TokenType != "Bearer" {
+		t.Errorf("token = %+v", token)
+	}
+	if time.Until(token.Expiry) < 50*time.Minute {
+		t.Errorf("expiry = %v, want about an hour from now", token.Expiry)
+	}
+}
@@ -2,15 +2,47 @@ package bus
 
 import (
 	"context"
+	"errors"
 	"sync"
 )
 
+// ErrBusClosed is returned when publishing to a bus that has been closed.
+var ErrBusClosed = errors.New("message bus closed")
+
+// Publisher is the publishing side of a bus, which is all a channel or a
+// background service needs. MockMessageBus implements it for tests.
+type Publisher interface {
+	PublishInbound(ctx context.Context, msg InboundMessage) error
+	PublishOutbound(ctx context.Context, msg OutboundMessage) error
+}
+
+// Bus carries messages between channels and the agent. MessageBus is the
+// in-process implementation; NATSBus carries them between processes.
+type Bus interface {
+	Publisher
+	ConsumeInbound(ctx context.Context) (InboundMessage, bool)
+	SubscribeOutbound(ctx context.Context) (OutboundMessage, bool)
+	Close()
+}
+
+var _ Bus = (*MessageBus)(nil)
+
 type MessageBus struct {
 	inbound  chan InboundMessage
 	outbound chan OutboundMessage
 	handlers map[string]MessageHandler
-	closed   bool
-	mu       sync.RWMutex
+	// subscribers receive an independent copy of every inbound message,
+	// in addition to the primary consumer reading via ConsumeInbound.
+	subscribers []chan InboundMessage
+	router      Router
+	closed      bool
+
+	inboundMiddleware  []func(InboundMessage) InboundMessage
+	outboundMiddleware []func(OutboundMessage) OutboundMessage
+
+	deadLetterMu   sync.Mutex
+	deadLetterPath string
+	mu             sync.RWMutex
 }
 
 func NewMessageBus() *MessageBus {
@@ -21,37 +53,145 @@ func NewMessageBus() *MessageBus {
 	}
 }
 
-func (mb *MessageBus) PublishInbound(msg InboundMessage) {
+// PublishInbound queues msg for the agent. It blocks while the inbound buffer
+// is full and returns ctx.Err() if ctx is done before the message is queued.
+// A message matching a route added with AddRoute goes to that route's handler
+// instead of the agent. Messages that cannot be queued are recorded in the
+// dead-letter file, if one is set. msg passes through the middleware added
+// with UseMiddleware first.
+func (mb *MessageBus) PublishInbound(ctx context.Context, msg InboundMessage) error {
+	err := mb.publishInbound(ctx, mb.applyInboundMiddleware(msg))
+	if err != nil {
+		// Record the message as published, so a replay runs the middleware once.
+		mb.recordDeadLetter(msg, deadLetterInbound, err.Error())
+	}
+	return err
+}
+
+func (mb *MessageBus) publishInbound(ctx context.Context, msg InboundMessage) error {
+	if handler, ok := mb.router.match(msg); ok {
+		return mb.publishRouted(ctx, msg, handler)
+	}
+
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
 	if mb.closed {
-		return
+		return ErrBusClosed
 	}
-	mb.inbound <- msg
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case mb.inbound <- msg:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	mb.offerSubscribers(msg)
+	return nil
 }
 
+// publishRouted hands msg to a route handler. The handler runs on the
+// publisher's goroutine without the bus lock held, so it may publish itself.
+func (mb *MessageBus) publishRouted(ctx context.Context, msg InboundMessage, handler func(InboundMessage)) error {
+	mb.mu.RLock()
+	if mb.closed {
+		mb.mu.RUnlock()
+		return ErrBusClosed
+	}
+	if err := ctx.Err(); err != nil {
+		mb.mu.RUnlock()
+		return err
+	}
+	mb.offerSubscribers(msg)
+	mb.mu.RUnlock()
+
+	handler(msg)
+	return nil
+}
+
+// offerSubscribers gives every subscriber a copy of msg. A copy dropped by a
+// subscriber that is not keeping up goes to the dead-letter file. It reports
+// whether every subscriber took its copy. The caller must hold mb.mu.
+func (mb *MessageBus) offerSubscribers(msg InboundMessage) bool {
+	delivered := true
+	for _, sub := range mb.subscribers {
+		if !offerInbound(sub, msg) {
+			mb.recordDeadLetter(msg, deadLetterSubscriber, "subscriber buffer full")
+			delivered = false
+		}
+	}
+	return delivered
+}
+
+// AddRoute sends inbound messages whose Metadata[key] matches value to
+// handler rather than to the default consumer. See Router.AddRoute.
+func (mb *MessageBus) AddRoute(key, value string, handler func(InboundMessage)) {
+	mb.router.AddRoute(key, value, handler)
+}
+
+// ConsumeInbound returns the next message for the agent. It reports false
+// once ctx is done or the bus is closed and drained.
 func (mb *MessageBus) ConsumeInbound(ctx context.Context) (InboundMessage, bool) {
 	select {
-	case msg := <-mb.inbound:
-		return msg, true
+	case msg, ok := <-mb.inbound:
+		return msg, ok
 	case <-ctx.Done():
 		return InboundMessage{}, false
 	}
 }
 
-func (mb *MessageBus) PublishOutbound(msg OutboundMessage) {
+// SubscribeInbound registers an additional inbound consumer, for example a
+// second AI backend used for A/B comparison. The returned channel receives a
+// copy of every message published after the call. A subscriber that falls
+// behind drops messages rather than blocking publishers or other subscribers.
+// The channel is closed when the bus is closed.
+//
+// Delivery has the same semantics as Split (per-subscriber copies, ordered,
+// dropped when the buffer is full) and shares offerInbound with it, but does
+// not go through Split itself: Split fixes its number of legs up front, while
+// subscribers register at any time, and each dropped copy here is written to
+// the dead-letter file.
+func (mb *MessageBus) SubscribeInbound() <-chan InboundMessage {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	ch := make(chan InboundMessage, splitBufferSize)
+	if mb.closed {
+		close(ch)
+		return ch
+	}
+	mb.subscribers = append(mb.subscribers, ch)
+	return ch
+}
+
+// PublishOutbound queues msg for delivery to a channel. It blocks while the
+// outbound buffer is full and returns ctx.Err() if ctx is done first.
+// A message without a TraceID takes the one carried by ctx, if any, before
+// it passes through the middleware added with UseOutboundMiddleware.
+func (mb *MessageBus) PublishOutbound(ctx context.Context, msg OutboundMessage) error {
+	if msg.TraceID == "" {
+		msg.TraceID = TraceIDFromContext(ctx)
+	}
+	msg = mb.applyOutboundMiddleware(msg)
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
 	if mb.closed {
-		return
+		return ErrBusClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case mb.outbound <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	mb.outbound <- msg
 }
 
 func (mb *MessageBus) SubscribeOutbound(ctx context.Context) (OutboundMessage, bool) {
 	select {
-	case msg := <-mb.outbound:
-		return msg, true
+	case msg, ok := <-mb.outbound:
+		return msg, ok
 	case <-ctx.Done():
 		return OutboundMessage{}, false
 	}
@@ -79,4 +219,7 @@ func (mb *MessageBus) Close() {
 	mb.closed = true
 	close(mb.inbound)
 	close(mb.outbound)
+	for _, sub := range mb.subscribers {
+		close(sub)
+	}
 }
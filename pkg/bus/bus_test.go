@@ -0,0 +1,69 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPublishInboundHonorsDeadline(t *testing.T) {
+	mb := NewMessageBus()
+	for i := 0; i < cap(mb.inbound); i++ {
+		if err := mb.PublishInbound(t.Context(), InboundMessage{}); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+
+	// The buffer is full, so the next publish must give up at the deadline
+	// instead of blocking forever.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := mb.PublishInbound(ctx, InboundMessage{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("PublishInbound on full bus = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestPublishOutboundCancelledContext(t *testing.T) {
+	mb := NewMessageBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := mb.PublishOutbound(ctx, OutboundMessage{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("PublishOutbound with cancelled ctx = %v, want Canceled", err)
+	}
+	if _, ok := mb.SubscribeOutbound(ctxWithTimeout(t, 50*time.Millisecond)); ok {
+		t.Fatal("cancelled publish should not queue a message")
+	}
+}
+
+func TestPublishAfterClose(t *testing.T) {
+	mb := NewMessageBus()
+	mb.Close()
+
+	if err := mb.PublishInbound(t.Context(), InboundMessage{}); !errors.Is(err, ErrBusClosed) {
+		t.Fatalf("PublishInbound after Close = %v, want ErrBusClosed", err)
+	}
+	if err := mb.PublishOutbound(t.Context(), OutboundMessage{}); !errors.Is(err, ErrBusClosed) {
+		t.Fatalf("PublishOutbound after Close = %v, want ErrBusClosed", err)
+	}
+}
+
+func TestConsumeAfterClose(t *testing.T) {
+	mb := NewMessageBus()
+	mb.Close()
+
+	if _, ok := mb.ConsumeInbound(t.Context()); ok {
+		t.Error("ConsumeInbound on a closed bus reported a message")
+	}
+	if _, ok := mb.SubscribeOutbound(t.Context()); ok {
+		t.Error("SubscribeOutbound on a closed bus reported a message")
+	}
+}
+
+func ctxWithTimeout(t *testing.T, d time.Duration) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	t.Cleanup(cancel)
+	return ctx
+}
@@ -0,0 +1,54 @@
+package buspb
+
+import "github.com/sipeed/picoclaw/pkg/bus"
+
+// The conversions live here rather than in package bus so that only the
+// transports that speak protobuf link the protobuf runtime.
+
+// FromInbound converts m to its protobuf form.
+func FromInbound(m bus.InboundMessage) *InboundMessage {
+	return &InboundMessage{
+		Channel:    m.Channel,
+		SenderId:   m.SenderID,
+		ChatId:     m.ChatID,
+		Content:    m.Content,
+		Media:      m.Media,
+		SessionKey: m.SessionKey,
+		Metadata:   m.Metadata,
+		TraceId:    m.TraceID,
+	}
+}
+
+// ToInbound is the inverse of FromInbound. A nil p gives the zero message.
+func ToInbound(p *InboundMessage) bus.InboundMessage {
+	return bus.InboundMessage{
+		Channel:    p.GetChannel(),
+		SenderID:   p.GetSenderId(),
+		ChatID:     p.GetChatId(),
+		Content:    p.GetContent(),
+		Media:      p.GetMedia(),
+		SessionKey: p.GetSessionKey(),
+		Metadata:   p.GetMetadata(),
+		TraceID:    p.GetTraceId(),
+	}
+}
+
+// FromOutbound converts m to its protobuf form.
+func FromOutbound(m bus.OutboundMessage) *OutboundMessage {
+	return &OutboundMessage{
+		Channel: m.Channel,
+		ChatId:  m.ChatID,
+		Content: m.Content,
+		TraceId: m.TraceID,
+	}
+}
+
+// ToOutbound is the inverse of FromOutbound.
+func ToOutbound(p *OutboundMessage) bus.OutboundMessage {
+	return bus.OutboundMessage{
+		Channel: p.GetChannel(),
+		ChatID:  p.GetChatId(),
+		Content: p.GetContent(),
+		TraceID: p.GetTraceId(),
+	}
+}
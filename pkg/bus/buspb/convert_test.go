@@ -0,0 +1,62 @@
+package buspb
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestInboundMessageRoundTrip(t *testing.T) {
+	want := bus.InboundMessage{
+		Channel:    "telegram",
+		SenderID:   "12345|alice",
+		ChatID:     "-100200",
+		Content:    "こんにちは",
+		Media:      []string{"/tmp/a.jpg", "/tmp/b.ogg"},
+		SessionKey: "telegram:-100200",
+		Metadata:   map[string]string{"message_id": "7", "is_group": "true"},
+		TraceID:    "0f8e1c2a-5b7d-4e3f-9a10-1234567890ab",
+	}
+
+	data, err := proto.Marshal(FromInbound(want))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var p InboundMessage
+	if err := proto.Unmarshal(data, &p); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got := ToInbound(&p); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestOutboundMessageRoundTrip(t *testing.T) {
+	want := bus.OutboundMessage{
+		Channel: "discord",
+		ChatID:  "987",
+		Content: "reply",
+		TraceID: "trace-1",
+	}
+
+	data, err := proto.Marshal(FromOutbound(want))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var p OutboundMessage
+	if err := proto.Unmarshal(data, &p); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got := ToOutbound(&p); got != want {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestToInboundNil(t *testing.T) {
+	if got := ToInbound(nil); !reflect.DeepEqual(got, bus.InboundMessage{}) {
+		t.Errorf("ToInbound(nil) = %+v", got)
+	}
+}
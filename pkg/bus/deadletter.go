@@ -0,0 +1,143 @@
+package bus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Dead-letter targets record which consumer missed a message.
+const (
+	deadLetterInbound    = "inbound"    // never reached the agent
+	deadLetterSubscriber = "subscriber" // dropped by a SubscribeInbound consumer
+)
+
+// deadLetterReplayTimeout bounds how long ReplayDeadLetter waits for room in
+// the inbound buffer for each message.
+const deadLetterReplayTimeout = 5 * time.Second
+
+// deadLetter is one line of the dead-letter file.
+type deadLetter struct {
+	Timestamp time.Time      `json:"ts"`
+	Reason    string         `json:"reason"`
+	Target    string         `json:"target"`
+	Message   InboundMessage `json:"message"`
+}
+
+// SetDeadLetterFile makes the bus append inbound messages it fails to deliver
+// to path, one JSON object per line. An empty path turns this off.
+func (mb *MessageBus) SetDeadLetterFile(path string) {
+	mb.deadLetterMu.Lock()
+	defer mb.deadLetterMu.Unlock()
+	mb.deadLetterPath = path
+}
+
+func (mb *MessageBus) recordDeadLetter(msg InboundMessage, target, reason string) {
+	mb.deadLetterMu.Lock()
+	defer mb.deadLetterMu.Unlock()
+	if mb.deadLetterPath == "" {
+		return
+	}
+
+	line, err := json.Marshal(deadLetter{
+		Timestamp: time.Now().UTC(),
+		Reason:    reason,
+		Target:    target,
+		Message:   msg,
+	})
+	if err == nil {
+		err = appendLine(mb.deadLetterPath, line)
+	}
+	if err != nil {
+		logger.ErrorCF("bus", "Failed to write dead letter", map[string]any{
+			"path":     mb.deadLetterPath,
+			"channel":  msg.Channel,
+			"trace_id": msg.TraceID,
+			"error":    err.Error(),
+		})
+	}
+}
+
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReplayDeadLetter re-publishes the messages in the dead-letter file at path
+// and returns how many were delivered. Messages that had reached the agent
+// but not a subscriber are offered to the subscribers only. The file is
+// removed before replaying, so messages that fail again are recorded afresh
+// when mb writes to the same file.
+func ReplayDeadLetter(path string, mb *MessageBus) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var letters []deadLetter
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSocketLineSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var dl deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &dl); err != nil {
+			return 0, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		letters = append(letters, dl)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, dl := range letters {
+		if dl.Target == deadLetterSubscriber {
+			if mb.redeliverToSubscribers(dl.Message) {
+				replayed++
+			}
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), deadLetterReplayTimeout)
+		err := mb.PublishInbound(ctx, dl.Message)
+		cancel()
+		if err == nil {
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+// redeliverToSubscribers offers msg to the current subscribers and reports
+// whether all of them accepted it.
+func (mb *MessageBus) redeliverToSubscribers(msg InboundMessage) bool {
+	mb.mu.RLock()
+	defer mb.mu.RUnlock()
+	if mb.closed {
+		mb.recordDeadLetter(msg, deadLetterSubscriber, ErrBusClosed.Error())
+		return false
+	}
+	return mb.offerSubscribers(msg)
+}
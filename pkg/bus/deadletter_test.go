@@ -0,0 +1,137 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open dead-letter file: %v", err)
+	}
+	defer f.Close()
+
+	var letters []deadLetter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var dl deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &dl); err != nil {
+			t.Fatalf("decode dead letter %q: %v", scanner.Text(), err)
+		}
+		letters = append(letters, dl)
+	}
+	return letters
+}
+
+func TestDeadLetterFile_RecordsAndReplaysDroppedMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	mb := NewMessageBus()
+	defer mb.Close()
+	mb.SetDeadLetterFile(path)
+
+	sub := mb.SubscribeInbound()
+
+	// Keep the primary consumer drained so only the subscriber falls behind.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			if _, ok := mb.ConsumeInbound(ctx); !ok {
+				return
+			}
+		}
+	}()
+
+	const extra = 5
+	for i := range splitBufferSize + extra {
+		msg := InboundMessage{Channel: "test", ChatID: "c1", Content: fmt.Sprintf("msg-%d", i)}
+		if err := mb.PublishInbound(context.Background(), msg); err != nil {
+			t.Fatalf("PublishInbound(%d): %v", i, err)
+		}
+	}
+
+	letters := readDeadLetters(t, path)
+	if len(letters) != extra {
+		t.Fatalf("dead letters = %d, want %d", len(letters), extra)
+	}
+	for i, dl := range letters {
+		want := fmt.Sprintf("msg-%d", splitBufferSize+i)
+		if dl.Message.Content != want {
+			t.Errorf("letter %d content = %q, want %q", i, dl.Message.Content, want)
+		}
+		if dl.Reason == "" || dl.Timestamp.IsZero() {
+			t.Errorf("letter %d missing reason or timestamp: %+v", i, dl)
+		}
+		if dl.Target != deadLetterSubscriber {
+			t.Errorf("letter %d target = %q, want %q", i, dl.Target, deadLetterSubscriber)
+		}
+	}
+
+	for range splitBufferSize {
+		<-sub
+	}
+
+	n, err := ReplayDeadLetter(path, mb)
+	if err != nil {
+		t.Fatalf("ReplayDeadLetter: %v", err)
+	}
+	if n != extra {
+		t.Fatalf("replayed = %d, want %d", n, extra)
+	}
+	for i := range extra {
+		msg := <-sub
+		want := fmt.Sprintf("msg-%d", splitBufferSize+i)
+		if msg.Content != want {
+			t.Errorf("replayed message %d = %q, want %q", i, msg.Content, want)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("dead-letter file still present after a clean replay: %v", err)
+	}
+}
+
+func TestDeadLetterFile_RecordsUnpublishedMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	mb := NewMessageBus()
+	mb.SetDeadLetterFile(path)
+	mb.Close()
+
+	if err := mb.PublishInbound(context.Background(), InboundMessage{Content: "late"}); err != ErrBusClosed {
+		t.Fatalf("PublishInbound() error = %v, want ErrBusClosed", err)
+	}
+
+	letters := readDeadLetters(t, path)
+	if len(letters) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(letters))
+	}
+	if letters[0].Target != deadLetterInbound || letters[0].Reason != ErrBusClosed.Error() {
+		t.Errorf("letter = %+v", letters[0])
+	}
+
+	replayBus := NewMessageBus()
+	defer replayBus.Close()
+	n, err := ReplayDeadLetter(path, replayBus)
+	if err != nil || n != 1 {
+		t.Fatalf("ReplayDeadLetter() = %d, %v; want 1, nil", n, err)
+	}
+	msg, ok := replayBus.ConsumeInbound(context.Background())
+	if !ok || msg.Content != "late" {
+		t.Errorf("replayed message = %+v, %v", msg, ok)
+	}
+}
+
+func TestReplayDeadLetter_MissingFile(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	n, err := ReplayDeadLetter(filepath.Join(t.TempDir(), "none.jsonl"), mb)
+	if err != nil || n != 0 {
+		t.Errorf("ReplayDeadLetter() = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,80 @@
+package bus
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// splitBufferSize is the per-leg buffer used by Split and inbound subscribers.
+// A leg whose buffer is full drops the message instead of stalling the others.
+const splitBufferSize = 100
+
+// FanOut merges several inbound streams into one. The returned channel is
+// closed once every source has been closed. Ordering is preserved per source.
+func FanOut(sources []<-chan InboundMessage) <-chan InboundMessage {
+	out := make(chan InboundMessage, splitBufferSize)
+
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for _, src := range sources {
+		go func(src <-chan InboundMessage) {
+			defer wg.Done()
+			for msg := range src {
+				out <- msg
+			}
+		}(src)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Split replicates every message from source onto n independent consumer
+// channels. Each consumer receives its own copy of the message, in the same
+// order as source. A consumer that stops reading only loses its own copies;
+// the other legs keep flowing. All legs are closed when source is closed.
+func Split(source <-chan InboundMessage, n int) []<-chan InboundMessage {
+	legs := make([]chan InboundMessage, n)
+	result := make([]<-chan InboundMessage, n)
+	for i := range legs {
+		legs[i] = make(chan InboundMessage, splitBufferSize)
+		result[i] = legs[i]
+	}
+
+	go func() {
+		for msg := range source {
+			for _, leg := range legs {
+				offerInbound(leg, msg)
+			}
+		}
+		for _, leg := range legs {
+			close(leg)
+		}
+	}()
+
+	return result
+}
+
+// offerInbound delivers a copy of msg to ch without blocking.
+// It reports false when the consumer is not keeping up and the copy was dropped.
+func offerInbound(ch chan InboundMessage, msg InboundMessage) bool {
+	select {
+	case ch <- cloneInbound(msg):
+		return true
+	default:
+		return false
+	}
+}
+
+// cloneInbound returns a copy of msg that shares no mutable state with it,
+// so one consumer editing Metadata or Media cannot affect another.
+func cloneInbound(msg InboundMessage) InboundMessage {
+	msg.Media = slices.Clone(msg.Media)
+	msg.Metadata = maps.Clone(msg.Metadata)
+	return msg
+}
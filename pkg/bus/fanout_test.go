@@ -0,0 +1,120 @@
+package bus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFanOutMergesAllSources(t *testing.T) {
+	a := make(chan InboundMessage, 10)
+	b := make(chan InboundMessage, 10)
+	for i := 0; i < 5; i++ {
+		a <- InboundMessage{Channel: "a", Content: fmt.Sprint(i)}
+		b <- InboundMessage{Channel: "b", Content: fmt.Sprint(i)}
+	}
+	close(a)
+	close(b)
+
+	next := map[string]int{}
+	for msg := range FanOut([]<-chan InboundMessage{a, b}) {
+		if want := fmt.Sprint(next[msg.Channel]); msg.Content != want {
+			t.Fatalf("source %s: got %q, want %q (ordering lost)", msg.Channel, msg.Content, want)
+		}
+		next[msg.Channel]++
+	}
+
+	if next["a"] != 5 || next["b"] != 5 {
+		t.Fatalf("received %v, want 5 from each source", next)
+	}
+}
+
+func TestSplitPreservesOrderPerLeg(t *testing.T) {
+	src := make(chan InboundMessage)
+	legs := Split(src, 3)
+
+	go func() {
+		for i := 0; i < 20; i++ {
+			src <- InboundMessage{Content: fmt.Sprint(i)}
+		}
+		close(src)
+	}()
+
+	for n, leg := range legs {
+		i := 0
+		for msg := range leg {
+			if msg.Content != fmt.Sprint(i) {
+				t.Fatalf("leg %d: got %q, want %q", n, msg.Content, fmt.Sprint(i))
+			}
+			i++
+		}
+		if i != 20 {
+			t.Fatalf("leg %d received %d messages, want 20", n, i)
+		}
+	}
+}
+
+func TestSplitDroppedConsumerDoesNotBlockOthers(t *testing.T) {
+	src := make(chan InboundMessage)
+	legs := Split(src, 2)
+
+	// Nobody ever reads legs[0]; legs[1] must keep receiving well past
+	// the point where the stalled leg's buffer is full.
+	for i := 0; i < splitBufferSize*3; i++ {
+		select {
+		case src <- InboundMessage{Content: fmt.Sprint(i)}:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("publish %d blocked by stalled consumer", i)
+		}
+		select {
+		case msg := <-legs[1]:
+			if msg.Content != fmt.Sprint(i) {
+				t.Fatalf("active leg got %q, want %q", msg.Content, fmt.Sprint(i))
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("active leg did not receive message %d", i)
+		}
+	}
+	close(src)
+}
+
+func TestSplitCopiesAreIndependent(t *testing.T) {
+	src := make(chan InboundMessage, 1)
+	legs := Split(src, 2)
+	src <- InboundMessage{Metadata: map[string]string{"k": "v"}}
+	close(src)
+
+	first := <-legs[0]
+	first.Metadata["k"] = "changed"
+	second := <-legs[1]
+	if second.Metadata["k"] != "v" {
+		t.Fatalf("metadata shared between legs: %q", second.Metadata["k"])
+	}
+}
+
+func TestSubscribeInboundReceivesCopies(t *testing.T) {
+	mb := NewMessageBus()
+	sub1 := mb.SubscribeInbound()
+	sub2 := mb.SubscribeInbound()
+
+	mb.PublishInbound(t.Context(), InboundMessage{Channel: "test", Content: "hello"})
+
+	if msg, ok := mb.ConsumeInbound(t.Context()); !ok || msg.Content != "hello" {
+		t.Fatalf("primary consumer got %+v, %v", msg, ok)
+	}
+	for i, sub := range []<-chan InboundMessage{sub1, sub2} {
+		select {
+		case msg := <-sub:
+			if msg.Content != "hello" {
+				t.Fatalf("subscriber %d got %q", i, msg.Content)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber %d received nothing", i)
+		}
+	}
+
+	mb.Close()
+	if _, ok := <-sub1; ok {
+		t.Fatal("subscriber channel should be closed with the bus")
+	}
+}
@@ -0,0 +1,66 @@
+package bus
+
+import "maps"
+
+// UseMiddleware adds mw to the chain every inbound message passes through
+// before it is routed or delivered to any consumer. Middleware runs in the
+// order it was added, each receiving the previous one's result.
+func (mb *MessageBus) UseMiddleware(mw func(InboundMessage) InboundMessage) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.inboundMiddleware = append(mb.inboundMiddleware, mw)
+}
+
+// UseOutboundMiddleware is UseMiddleware for outbound messages.
+func (mb *MessageBus) UseOutboundMiddleware(mw func(OutboundMessage) OutboundMessage) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.outboundMiddleware = append(mb.outboundMiddleware, mw)
+}
+
+// The chains are copied out under the lock and run without it, so
+// middleware may use the bus itself.
+
+func (mb *MessageBus) applyInboundMiddleware(msg InboundMessage) InboundMessage {
+	mb.mu.RLock()
+	chain := mb.inboundMiddleware
+	mb.mu.RUnlock()
+	for _, mw := range chain {
+		msg = mw(msg)
+	}
+	return msg
+}
+
+func (mb *MessageBus) applyOutboundMiddleware(msg OutboundMessage) OutboundMessage {
+	mb.mu.RLock()
+	chain := mb.outboundMiddleware
+	mb.mu.RUnlock()
+	for _, mw := range chain {
+		msg = mw(msg)
+	}
+	return msg
+}
+
+// MetadataSystemPromptOverride is the metadata key SystemPromptOverrides
+// sets on a message, holding the system prompt to use for it. The agent loop
+// uses it in place of the workspace's static system prompt.
+const MetadataSystemPromptOverride = "system_prompt_override"
+
+// SystemPromptOverrides returns inbound middleware that looks up each
+// message's Metadata["event"] in overrides and, on a match, stores the
+// prompt under MetadataSystemPromptOverride. The metadata map is copied
+// first, since the publisher may still hold it.
+func SystemPromptOverrides(overrides map[string]string) func(InboundMessage) InboundMessage {
+	return func(msg InboundMessage) InboundMessage {
+		event := msg.Metadata["event"]
+		prompt, ok := overrides[event]
+		if event == "" || !ok {
+			return msg
+		}
+		metadata := make(map[string]string, len(msg.Metadata)+1)
+		maps.Copy(metadata, msg.Metadata)
+		metadata[MetadataSystemPromptOverride] = prompt
+		msg.Metadata = metadata
+		return msg
+	}
+}
@@ -0,0 +1,97 @@
+package bus
+
+import (
+	"testing"
+)
+
+func TestMiddlewareComposesInOrder(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	sub := mb.SubscribeInbound()
+
+	mb.UseMiddleware(func(msg InboundMessage) InboundMessage {
+		msg.Content += " [first]"
+		return msg
+	})
+	mb.UseMiddleware(func(msg InboundMessage) InboundMessage {
+		msg.Content += " [second]"
+		return msg
+	})
+	mb.UseOutboundMiddleware(func(msg OutboundMessage) OutboundMessage {
+		msg.Content += " [out-first]"
+		return msg
+	})
+	mb.UseOutboundMiddleware(func(msg OutboundMessage) OutboundMessage {
+		msg.Content += " [out-second]"
+		return msg
+	})
+
+	if err := mb.PublishInbound(t.Context(), InboundMessage{Content: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mb.PublishOutbound(t.Context(), OutboundMessage{Content: "reply"}); err != nil {
+		t.Fatal(err)
+	}
+
+	const wantIn = "hi [first] [second]"
+	if msg, _ := mb.ConsumeInbound(t.Context()); msg.Content != wantIn {
+		t.Errorf("consumer got %q, want %q", msg.Content, wantIn)
+	}
+	if msg := <-sub; msg.Content != wantIn {
+		t.Errorf("subscriber got %q, want %q", msg.Content, wantIn)
+	}
+	if msg, _ := mb.SubscribeOutbound(t.Context()); msg.Content != "reply [out-first] [out-second]" {
+		t.Errorf("outbound got %q", msg.Content)
+	}
+}
+
+func TestMiddlewareRunsBeforeRouting(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	mb.UseMiddleware(func(msg InboundMessage) InboundMessage {
+		msg.Metadata = map[string]string{"tier": "gold"}
+		return msg
+	})
+	var routed []string
+	mb.AddRoute("tier", "gold", func(msg InboundMessage) { routed = append(routed, msg.Content) })
+
+	if err := mb.PublishInbound(t.Context(), InboundMessage{Content: "vip"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(routed) != 1 || routed[0] != "vip" {
+		t.Errorf("routed = %v, want the message tagged by middleware", routed)
+	}
+}
+
+func TestSystemPromptOverrides(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	mb.UseMiddleware(SystemPromptOverrides(map[string]string{
+		"superchat": "Thank the viewer warmly.",
+	}))
+
+	superchat := map[string]string{"event": "superchat", "amount": "500"}
+	for _, msg := range []InboundMessage{
+		{Content: "paid", Metadata: superchat},
+		{Content: "follow", Metadata: map[string]string{"event": "follow"}},
+		{Content: "plain"},
+	} {
+		if err := mb.PublishInbound(t.Context(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg, _ := mb.ConsumeInbound(t.Context())
+	if msg.Metadata[MetadataSystemPromptOverride] != "Thank the viewer warmly." || msg.Metadata["amount"] != "500" {
+		t.Errorf("superchat metadata = %v", msg.Metadata)
+	}
+	if _, ok := superchat[MetadataSystemPromptOverride]; ok {
+		t.Error("publisher's metadata map was modified")
+	}
+	for range 2 {
+		if msg, _ := mb.ConsumeInbound(t.Context()); msg.Metadata[MetadataSystemPromptOverride] != "" {
+			t.Errorf("%s metadata = %v, want no override", msg.Content, msg.Metadata)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package bus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+var _ Publisher = (*MockMessageBus)(nil)
+
+// MockMessageBus records published messages in memory instead of queueing
+// them, so a test can inspect what was published as soon as the publishing
+// call returns. Publishing never blocks and never fails.
+type MockMessageBus struct {
+	mu       sync.Mutex
+	inbound  []InboundMessage
+	outbound []OutboundMessage
+	// published is closed and replaced after each inbound publish, waking
+	// ExpectInbound callers.
+	published chan struct{}
+}
+
+func NewMockBus() *MockMessageBus {
+	return &MockMessageBus{published: make(chan struct{})}
+}
+
+func (m *MockMessageBus) PublishInbound(ctx context.Context, msg InboundMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inbound = append(m.inbound, msg)
+	close(m.published)
+	m.published = make(chan struct{})
+	return nil
+}
+
+func (m *MockMessageBus) PublishOutbound(ctx context.Context, msg OutboundMessage) error {
+	if msg.TraceID == "" {
+		msg.TraceID = TraceIDFromContext(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outbound = append(m.outbound, msg)
+	return nil
+}
+
+// InboundMessages returns a copy of the inbound messages published so far.
+func (m *MockMessageBus) InboundMessages() []InboundMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]InboundMessage(nil), m.inbound...)
+}
+
+// OutboundMessages returns a copy of the outbound messages published so far.
+func (m *MockMessageBus) OutboundMessages() []OutboundMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]OutboundMessage(nil), m.outbound...)
+}
+
+// ExpectInbound waits until at least n inbound messages have been published
+// and returns the first n. It fails the test if that takes longer than
+// timeout.
+func (m *MockMessageBus) ExpectInbound(t testing.TB, n int, timeout time.Duration) []InboundMessage {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		m.mu.Lock()
+		if len(m.inbound) >= n {
+			msgs := append([]InboundMessage(nil), m.inbound[:n]...)
+			m.mu.Unlock()
+			return msgs
+		}
+		got, published := len(m.inbound), m.published
+		m.mu.Unlock()
+
+		select {
+		case <-published:
+		case <-deadline.C:
+			t.Fatalf("got %d inbound messages within %v, want %d", got, timeout, n)
+			return nil
+		}
+	}
+}
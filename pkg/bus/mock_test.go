@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMockBusRecordsMessages(t *testing.T) {
+	mb := NewMockBus()
+	ctx := WithTraceID(context.Background(), "t1")
+
+	mb.PublishInbound(ctx, InboundMessage{Content: "in"})
+	mb.PublishOutbound(ctx, OutboundMessage{Content: "out"})
+
+	in := mb.InboundMessages()
+	if len(in) != 1 || in[0].Content != "in" {
+		t.Errorf("InboundMessages() = %+v", in)
+	}
+	out := mb.OutboundMessages()
+	if len(out) != 1 || out[0].Content != "out" || out[0].TraceID != "t1" {
+		t.Errorf("OutboundMessages() = %+v", out)
+	}
+
+	// The returned slices are copies.
+	in[0].Content = "changed"
+	if mb.InboundMessages()[0].Content != "in" {
+		t.Error("InboundMessages() exposed internal storage")
+	}
+}
+
+func TestMockBusExpectInboundWaits(t *testing.T) {
+	mb := NewMockBus()
+	go func() {
+		for _, s := range []string{"a", "b", "c"} {
+			time.Sleep(5 * time.Millisecond)
+			mb.PublishInbound(context.Background(), InboundMessage{Content: s})
+		}
+	}()
+
+	msgs := mb.ExpectInbound(t, 2, time.Second)
+	if len(msgs) != 2 || msgs[0].Content != "a" || msgs[1].Content != "b" {
+		t.Errorf("ExpectInbound() = %+v", msgs)
+	}
+}
+
+// fatalRecorder captures Fatalf so the timeout path can be checked without
+// failing the enclosing test.
+type fatalRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(string, ...any) { r.failed = true }
+
+func TestMockBusExpectInboundTimeout(t *testing.T) {
+	mb := NewMockBus()
+	mb.PublishInbound(context.Background(), InboundMessage{})
+
+	rec := &fatalRecorder{TB: t}
+	if msgs := mb.ExpectInbound(rec, 2, 10*time.Millisecond); msgs != nil || !rec.failed {
+		t.Errorf("ExpectInbound() = %+v, failed = %v; want nil, true", msgs, rec.failed)
+	}
+}
@@ -0,0 +1,236 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Subjects used by NATSBus within its stream.
+const (
+	NATSInboundSubject  = "picoclaw.inbound"
+	NATSOutboundSubject = "picoclaw.outbound"
+)
+
+// DefaultNATSStream is the stream NATSBus uses when none is named.
+const DefaultNATSStream = "PICOCLAW"
+
+const natsSetupTimeout = 10 * time.Second
+
+// natsAckWait is how long the server waits for an ack before redelivering.
+// A message waiting for a receiver is kept alive with InProgress at a third
+// of this interval, since an agent turn can easily outlast it.
+var natsAckWait = 30 * time.Second
+
+// NATSBus is a Bus backed by a NATS JetStream stream, so channels and the
+// agent can run in separate processes. Messages are stored as JSON on
+// NATSInboundSubject and NATSOutboundSubject. The stream is a work queue:
+// every process consuming from the same stream shares one durable consumer
+// per direction, and each message is delivered to exactly one of them.
+//
+// Routes, subscribers, middleware and the dead-letter file are features of the
+// in-process MessageBus and are not available here.
+type NATSBus struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	inbound  natsDirection[InboundMessage]
+	outbound natsDirection[OutboundMessage]
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+var _ Bus = (*NATSBus)(nil)
+
+// natsDirection delivers one subject's messages to ConsumeInbound or
+// SubscribeOutbound. Consumption starts on first use, so a process that only
+// publishes in a direction does not take messages meant for another process.
+type natsDirection[T any] struct {
+	consumer jetstream.Consumer
+	ch       chan T
+
+	once sync.Once
+	cc   jetstream.ConsumeContext
+	err  error
+}
+
+// NewNATSBus connects to the NATS server at url and creates streamName, with
+// the picoclaw subjects, if it does not exist yet. An empty streamName means
+// DefaultNATSStream.
+func NewNATSBus(url, streamName string) (*NATSBus, error) {
+	if streamName == "" {
+		streamName = DefaultNATSStream
+	}
+
+	conn, err := nats.Connect(url, nats.Name("picoclaw"))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open jetstream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+
+	stream, err := js.Stream(ctx, streamName)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		stream, err = js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:      streamName,
+			Subjects:  []string{NATSInboundSubject, NATSOutboundSubject},
+			Retention: jetstream.WorkQueuePolicy,
+		})
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open stream %s: %w", streamName, err)
+	}
+
+	b := &NATSBus{conn: conn, js: js, done: make(chan struct{})}
+	b.inbound.consumer, err = natsConsumer(ctx, stream, "picoclaw-inbound", NATSInboundSubject)
+	if err == nil {
+		b.outbound.consumer, err = natsConsumer(ctx, stream, "picoclaw-outbound", NATSOutboundSubject)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	b.inbound.ch = make(chan InboundMessage)
+	b.outbound.ch = make(chan OutboundMessage)
+	return b, nil
+}
+
+func natsConsumer(ctx context.Context, stream jetstream.Stream, name, subject string) (jetstream.Consumer, error) {
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       name,
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       natsAckWait,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create consumer %s: %w", name, err)
+	}
+	return consumer, nil
+}
+
+func (b *NATSBus) PublishInbound(ctx context.Context, msg InboundMessage) error {
+	return b.publish(ctx, NATSInboundSubject, msg)
+}
+
+// PublishOutbound publishes msg. As with MessageBus, a message without a
+// TraceID takes the one carried by ctx, if any.
+func (b *NATSBus) PublishOutbound(ctx context.Context, msg OutboundMessage) error {
+	if msg.TraceID == "" {
+		msg.TraceID = TraceIDFromContext(ctx)
+	}
+	return b.publish(ctx, NATSOutboundSubject, msg)
+}
+
+func (b *NATSBus) publish(ctx context.Context, subject string, msg any) error {
+	if b.isClosed() {
+		return ErrBusClosed
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = b.js.Publish(ctx, subject, data)
+	return err
+}
+
+func (b *NATSBus) ConsumeInbound(ctx context.Context) (InboundMessage, bool) {
+	return natsReceive(ctx, b, &b.inbound)
+}
+
+func (b *NATSBus) SubscribeOutbound(ctx context.Context) (OutboundMessage, bool) {
+	return natsReceive(ctx, b, &b.outbound)
+}
+
+// natsReceive waits for the next message in d. A message is acknowledged
+// once it has been handed to a caller, and marked in progress while it waits
+// so the server does not redeliver it; one still waiting when the bus closes
+// is returned to the stream for redelivery.
+func natsReceive[T any](ctx context.Context, b *NATSBus, d *natsDirection[T]) (T, bool) {
+	var zero T
+	d.once.Do(func() {
+		d.cc, d.err = d.consumer.Consume(func(m jetstream.Msg) {
+			var msg T
+			if err := json.Unmarshal(m.Data(), &msg); err != nil {
+				logger.WarnCF("bus", "Dropping malformed NATS message", map[string]any{
+					"subject": m.Subject(),
+					"error":   err.Error(),
+				})
+				m.Term()
+				return
+			}
+			progress := time.NewTicker(natsAckWait / 3)
+			defer progress.Stop()
+			for {
+				select {
+				case d.ch <- msg:
+					m.Ack()
+					return
+				case <-progress.C:
+					m.InProgress()
+				case <-b.done:
+					m.Nak()
+					return
+				}
+			}
+		}, jetstream.PullMaxMessages(1))
+	})
+	if errors.Is(d.err, ErrBusClosed) {
+		return zero, false
+	}
+	if d.err != nil {
+		logger.ErrorCF("bus", "Failed to consume from NATS", map[string]any{"error": d.err.Error()})
+		return zero, false
+	}
+
+	select {
+	case msg := <-d.ch:
+		return msg, true
+	case <-ctx.Done():
+		return zero, false
+	case <-b.done:
+		return zero, false
+	}
+}
+
+// stop ends consumption, or prevents it from starting.
+func (d *natsDirection[T]) stop() {
+	d.once.Do(func() { d.err = ErrBusClosed })
+	if d.cc != nil {
+		d.cc.Stop()
+	}
+}
+
+func (b *NATSBus) isClosed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops consuming and disconnects from the server. Messages already in
+// the stream stay there for the next consumer.
+func (b *NATSBus) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+		b.inbound.stop()
+		b.outbound.stop()
+		b.conn.Close()
+	})
+}
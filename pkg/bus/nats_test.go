@@ -0,0 +1,174 @@
+package bus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// runNATSServer starts an embedded JetStream-enabled server on a random port.
+func runNATSServer(t *testing.T) string {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("create nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv.ClientURL()
+}
+
+func newTestNATSBus(t *testing.T, url string) *NATSBus {
+	t.Helper()
+	b, err := NewNATSBus(url, "TEST")
+	if err != nil {
+		t.Fatalf("NewNATSBus: %v", err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+func natsTestContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestNATSBusRoundTrip(t *testing.T) {
+	b := newTestNATSBus(t, runNATSServer(t))
+	ctx := natsTestContext(t)
+
+	in := InboundMessage{
+		Channel:  "telegram",
+		SenderID: "u1",
+		ChatID:   "c1",
+		Content:  "hello",
+		Metadata: map[string]string{"k": "v"},
+		TraceID:  "t1",
+	}
+	if err := b.PublishInbound(ctx, in); err != nil {
+		t.Fatalf("PublishInbound: %v", err)
+	}
+	got, ok := b.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("ConsumeInbound returned false")
+	}
+	if got.Content != in.Content || got.Metadata["k"] != "v" || got.TraceID != "t1" {
+		t.Errorf("ConsumeInbound = %+v, want %+v", got, in)
+	}
+
+	if err := b.PublishOutbound(WithTraceID(ctx, "t1"), OutboundMessage{Channel: "telegram", Content: "hi"}); err != nil {
+		t.Fatalf("PublishOutbound: %v", err)
+	}
+	out, ok := b.SubscribeOutbound(ctx)
+	if !ok || out.Content != "hi" || out.TraceID != "t1" {
+		t.Errorf("SubscribeOutbound = %+v, %v", out, ok)
+	}
+}
+
+// TestNATSBusAcrossProcesses stands in for a gateway and a separate agent
+// process sharing one stream.
+func TestNATSBusAcrossProcesses(t *testing.T) {
+	url := runNATSServer(t)
+	gateway := newTestNATSBus(t, url)
+	agent := newTestNATSBus(t, url)
+	ctx := natsTestContext(t)
+
+	for _, content := range []string{"one", "two", "three"} {
+		if err := gateway.PublishInbound(ctx, InboundMessage{Content: content}); err != nil {
+			t.Fatalf("PublishInbound: %v", err)
+		}
+	}
+	for _, want := range []string{"one", "two", "three"} {
+		msg, ok := agent.ConsumeInbound(ctx)
+		if !ok || msg.Content != want {
+			t.Fatalf("agent got %+v, %v; want %q", msg, ok, want)
+		}
+		if err := agent.PublishOutbound(ctx, OutboundMessage{Content: "re: " + msg.Content}); err != nil {
+			t.Fatalf("PublishOutbound: %v", err)
+		}
+	}
+	for _, want := range []string{"re: one", "re: two", "re: three"} {
+		msg, ok := gateway.SubscribeOutbound(ctx)
+		if !ok || msg.Content != want {
+			t.Fatalf("gateway got %+v, %v; want %q", msg, ok, want)
+		}
+	}
+}
+
+func TestNATSBusUnconsumedMessageSurvivesClose(t *testing.T) {
+	url := runNATSServer(t)
+	ctx := natsTestContext(t)
+
+	first := newTestNATSBus(t, url)
+	if err := first.PublishInbound(ctx, InboundMessage{Content: "kept"}); err != nil {
+		t.Fatalf("PublishInbound: %v", err)
+	}
+	first.Close()
+
+	if err := first.PublishInbound(ctx, InboundMessage{}); err != ErrBusClosed {
+		t.Errorf("PublishInbound after Close = %v, want ErrBusClosed", err)
+	}
+	if _, ok := first.ConsumeInbound(ctx); ok {
+		t.Error("ConsumeInbound after Close returned a message")
+	}
+
+	second := newTestNATSBus(t, url)
+	msg, ok := second.ConsumeInbound(ctx)
+	if !ok || msg.Content != "kept" {
+		t.Errorf("ConsumeInbound = %+v, %v; want the message published before Close", msg, ok)
+	}
+}
+
+func TestNATSBusConsumeStopsWithContext(t *testing.T) {
+	b := newTestNATSBus(t, runNATSServer(t))
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, ok := b.ConsumeInbound(ctx); ok {
+		t.Error("ConsumeInbound returned a message from an empty stream")
+	}
+}
+
+// TestNATSBusSlowReceiverGetsNoDuplicates holds messages unconsumed for
+// several AckWait periods, as a busy agent does, and checks none is redelivered.
+func TestNATSBusSlowReceiverGetsNoDuplicates(t *testing.T) {
+	orig := natsAckWait
+	natsAckWait = 300 * time.Millisecond
+	t.Cleanup(func() { natsAckWait = orig })
+
+	b := newTestNATSBus(t, runNATSServer(t))
+	ctx, cancel := context.WithTimeout(t.Context(), 15*time.Second)
+	defer cancel()
+
+	want := []string{"one", "two", "three"}
+	for _, content := range want {
+		if err := b.PublishInbound(ctx, InboundMessage{Content: content}); err != nil {
+			t.Fatalf("PublishInbound: %v", err)
+		}
+	}
+	for _, content := range want {
+		msg, ok := b.ConsumeInbound(ctx)
+		if !ok || msg.Content != content {
+			t.Fatalf("ConsumeInbound = %+v, %v; want %q", msg, ok, content)
+		}
+		time.Sleep(3 * natsAckWait)
+	}
+
+	short, cancelShort := context.WithTimeout(ctx, 3*natsAckWait)
+	defer cancelShort()
+	if msg, ok := b.ConsumeInbound(short); ok {
+		t.Errorf("redelivered %+v after a slow receive", msg)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build generate_proto
+
+// This file only carries the generate directive, behind a build tag so that
+// go generate ./... does not require protoc.
+
+package bus
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/sipeed/picoclaw/pkg/bus bus.proto
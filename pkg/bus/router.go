@@ -0,0 +1,42 @@
+package bus
+
+import "sync"
+
+// Router dispatches inbound messages to handlers by metadata. Routes are
+// checked in registration order and the first match wins, so register the
+// most specific routes first.
+type Router struct {
+	mu     sync.RWMutex
+	routes []route
+}
+
+type route struct {
+	key     string
+	value   string
+	handler func(InboundMessage)
+}
+
+// AddRoute registers handler for messages whose Metadata[key] equals value.
+// An empty value matches any message that carries a non-empty key, which
+// suits metadata such as amounts whose exact value varies.
+func (r *Router) AddRoute(key, value string, handler func(InboundMessage)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route{key: key, value: value, handler: handler})
+}
+
+// match returns the handler of the first route matching msg.
+func (r *Router) match(msg InboundMessage) (func(InboundMessage), bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rt := range r.routes {
+		v, ok := msg.Metadata[rt.key]
+		if !ok {
+			continue
+		}
+		if (rt.value == "" && v != "") || (rt.value != "" && v == rt.value) {
+			return rt.handler, true
+		}
+	}
+	return nil, false
+}
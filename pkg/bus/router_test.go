@@ -0,0 +1,122 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRouterFirstMatchWins(t *testing.T) {
+	mb := NewMessageBus()
+
+	var specific, generic atomic.Int32
+	mb.AddRoute("tier", "gold", func(InboundMessage) { specific.Add(1) })
+	mb.AddRoute("tier", "", func(InboundMessage) { generic.Add(1) })
+	// Never reached: the route above already matches every "tier" message.
+	mb.AddRoute("tier", "silver", func(InboundMessage) { t.Error("shadowed route was called") })
+
+	for _, tier := range []string{"gold", "silver", "bronze"} {
+		msg := InboundMessage{Metadata: map[string]string{"tier": tier}}
+		if err := mb.PublishInbound(t.Context(), msg); err != nil {
+			t.Fatalf("publish %s: %v", tier, err)
+		}
+	}
+
+	if n := specific.Load(); n != 1 {
+		t.Errorf("specific route calls = %d, want 1", n)
+	}
+	if n := generic.Load(); n != 2 {
+		t.Errorf("generic route calls = %d, want 2", n)
+	}
+	if n := len(mb.inbound); n != 0 {
+		t.Errorf("default consumer received %d routed messages", n)
+	}
+}
+
+func TestRouterFallsBackToDefault(t *testing.T) {
+	mb := NewMessageBus()
+	mb.AddRoute("super_chat_amount", "", func(InboundMessage) { t.Error("unexpected route call") })
+
+	msgs := []InboundMessage{
+		{Content: "no metadata"},
+		{Content: "other key", Metadata: map[string]string{"event": "follow"}},
+		{Content: "empty value", Metadata: map[string]string{"super_chat_amount": ""}},
+	}
+	for _, msg := range msgs {
+		if err := mb.PublishInbound(t.Context(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range msgs {
+		got, ok := mb.ConsumeInbound(t.Context())
+		if !ok || got.Content != want.Content {
+			t.Errorf("ConsumeInbound = %q, %v; want %q", got.Content, ok, want.Content)
+		}
+	}
+}
+
+func TestRouterConcurrentPublish(t *testing.T) {
+	mb := NewMessageBus()
+
+	var mu sync.Mutex
+	routed := make(map[string]int)
+	mb.AddRoute("kind", "priority", func(msg InboundMessage) {
+		mu.Lock()
+		routed[msg.Content]++
+		mu.Unlock()
+	})
+
+	const publishers, perPublisher = 8, 50
+	var wg sync.WaitGroup
+	for p := range publishers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perPublisher {
+				msg := InboundMessage{Content: fmt.Sprintf("%d-%d", p, i)}
+				if i%2 == 0 {
+					msg.Metadata = map[string]string{"kind": "priority"}
+				}
+				if err := mb.PublishInbound(context.Background(), msg); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	// Drain the default consumer while publishers run so it never fills up.
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	defaults := 0
+	for defaults < publishers*perPublisher/2 {
+		if _, ok := mb.ConsumeInbound(ctx); !ok {
+			t.Fatalf("timed out after %d default messages", defaults)
+		}
+		defaults++
+	}
+	wg.Wait()
+
+	if len(routed) != publishers*perPublisher/2 {
+		t.Errorf("routed %d distinct messages, want %d", len(routed), publishers*perPublisher/2)
+	}
+	for content, n := range routed {
+		if n != 1 {
+			t.Errorf("message %s routed %d times", content, n)
+		}
+	}
+}
+
+func TestRouterClosedBus(t *testing.T) {
+	mb := NewMessageBus()
+	mb.AddRoute("k", "v", func(InboundMessage) { t.Error("handler called on closed bus") })
+	mb.Close()
+	err := mb.PublishInbound(t.Context(), InboundMessage{Metadata: map[string]string{"k": "v"}})
+	if !errors.Is(err, ErrBusClosed) {
+		t.Errorf("PublishInbound = %v, want ErrBusClosed", err)
+	}
+}
@@ -0,0 +1,106 @@
+package bus
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// BusSpy records the messages passing through a MessageBus without taking
+// them from its consumers, so a test can assert on what channels published
+// while the bus keeps working normally. Inbound messages are read from an
+// inbound subscription; outbound messages are seen by a pass-through
+// outbound middleware.
+type BusSpy struct {
+	mu       sync.Mutex
+	inbound  []InboundMessage
+	outbound []OutboundMessage
+	// recorded is closed and replaced after each inbound message is
+	// recorded, waking AssertInbound callers.
+	recorded chan struct{}
+}
+
+// NewSpy starts recording b. Only messages published after the call are
+// seen. Recording stops when b is closed.
+func NewSpy(b *MessageBus) *BusSpy {
+	s := &BusSpy{recorded: make(chan struct{})}
+	sub := b.SubscribeInbound()
+	b.UseOutboundMiddleware(func(msg OutboundMessage) OutboundMessage {
+		s.mu.Lock()
+		s.outbound = append(s.outbound, msg)
+		s.mu.Unlock()
+		return msg
+	})
+	go func() {
+		for msg := range sub {
+			s.mu.Lock()
+			s.inbound = append(s.inbound, msg)
+			close(s.recorded)
+			s.recorded = make(chan struct{})
+			s.mu.Unlock()
+		}
+	}()
+	return s
+}
+
+// InboundCount returns the number of inbound messages recorded so far.
+func (s *BusSpy) InboundCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.inbound)
+}
+
+// InboundMessages returns a copy of the inbound messages recorded so far.
+func (s *BusSpy) InboundMessages() []InboundMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]InboundMessage(nil), s.inbound...)
+}
+
+// OutboundMessages returns a copy of the outbound messages recorded so far.
+func (s *BusSpy) OutboundMessages() []OutboundMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]OutboundMessage(nil), s.outbound...)
+}
+
+// AssertInbound waits until at least n inbound messages have been recorded
+// and returns the first n. It fails the test if that takes longer than
+// timeout.
+func (s *BusSpy) AssertInbound(t testing.TB, n int, timeout time.Duration) []InboundMessage {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		if len(s.inbound) >= n {
+			msgs := append([]InboundMessage(nil), s.inbound[:n]...)
+			s.mu.Unlock()
+			return msgs
+		}
+		got, recorded := len(s.inbound), s.recorded
+		s.mu.Unlock()
+
+		select {
+		case <-recorded:
+		case <-deadline.C:
+			t.Fatalf("got %d inbound messages within %v, want %d", got, timeout, n)
+			return nil
+		}
+	}
+}
+
+// AssertInboundContains fails the test unless an inbound message recorded so
+// far has content containing the given text.
+func (s *BusSpy) AssertInboundContains(t testing.TB, content string) {
+	t.Helper()
+	msgs := s.InboundMessages()
+	for _, msg := range msgs {
+		if strings.Contains(msg.Content, content) {
+			return
+		}
+	}
+	t.Fatalf("no inbound message among %d contains %q", len(msgs), content)
+}
@@ -0,0 +1,41 @@
+package bus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusSpyRecordsWithoutConsuming(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	spy := NewSpy(mb)
+
+	for _, content := range []string{"first", "second"} {
+		if err := mb.PublishInbound(t.Context(), InboundMessage{Channel: "test", Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mb.PublishOutbound(t.Context(), OutboundMessage{Channel: "test", Content: "reply"}); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := spy.AssertInbound(t, 2, time.Second)
+	if msgs[0].Content != "first" || msgs[1].Content != "second" {
+		t.Errorf("recorded %q, %q", msgs[0].Content, msgs[1].Content)
+	}
+	spy.AssertInboundContains(t, "econ")
+	if n := spy.InboundCount(); n != 2 {
+		t.Errorf("InboundCount = %d, want 2", n)
+	}
+	if out := spy.OutboundMessages(); len(out) != 1 || out[0].Content != "reply" {
+		t.Errorf("OutboundMessages = %+v", out)
+	}
+
+	// The agent still gets every message.
+	if msg, ok := mb.ConsumeInbound(t.Context()); !ok || msg.Content != "first" {
+		t.Errorf("ConsumeInbound = %+v, %v", msg, ok)
+	}
+	if msg, ok := mb.SubscribeOutbound(t.Context()); !ok || msg.Content != "reply" {
+		t.Errorf("SubscribeOutbound = %+v, %v", msg, ok)
+	}
+}
@@ -0,0 +1,34 @@
+package bus
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of ctx carrying traceID. Outbound messages
+// published with that context inherit it when they have no TraceID set.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID stored by WithTraceID, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// NewTraceID returns a random UUID (version 4) for correlating an inbound
+// message with the replies it produces.
+func NewTraceID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,50 @@
+package bus
+
+import (
+	"regexp"
+	"testing"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewTraceID(t *testing.T) {
+	a, b := NewTraceID(), NewTraceID()
+	if !uuidV4.MatchString(a) {
+		t.Errorf("NewTraceID() = %q, want a version 4 UUID", a)
+	}
+	if a == b {
+		t.Errorf("NewTraceID returned %q twice", a)
+	}
+}
+
+func TestTraceIDContext(t *testing.T) {
+	if id := TraceIDFromContext(t.Context()); id != "" {
+		t.Errorf("empty context trace ID = %q", id)
+	}
+	ctx := WithTraceID(t.Context(), "abc")
+	if id := TraceIDFromContext(ctx); id != "abc" {
+		t.Errorf("TraceIDFromContext = %q, want abc", id)
+	}
+	if WithTraceID(t.Context(), "") != t.Context() {
+		t.Error("WithTraceID with an empty ID should return ctx unchanged")
+	}
+}
+
+func TestPublishOutboundInheritsTraceID(t *testing.T) {
+	mb := NewMessageBus()
+	ctx := WithTraceID(t.Context(), "from-ctx")
+
+	if err := mb.PublishOutbound(ctx, OutboundMessage{Content: "inherit"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mb.PublishOutbound(ctx, OutboundMessage{Content: "explicit", TraceID: "own"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"from-ctx", "own"} {
+		msg, _ := mb.SubscribeOutbound(t.Context())
+		if msg.TraceID != want {
+			t.Errorf("%s: TraceID = %q, want %q", msg.Content, msg.TraceID, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxSocketLineSize bounds a single newline-delimited JSON message read from
+// a socket client.
+const maxSocketLineSize = 1 << 20
+
+// UnixServer serves a MessageBus over a Unix domain socket. Each connected
+// client receives inbound messages as newline-delimited JSON and writes
+// OutboundMessage lines back, which are published to the bus. Clients take
+// the place of the agent: every inbound message goes to exactly one client.
+type UnixServer struct {
+	bus      *MessageBus
+	path     string
+	listener *net.UnixListener
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[*net.UnixConn]struct{}
+}
+
+// ListenUnix starts serving the bus on a stream socket at path. A stale
+// socket left behind by a previous run is replaced.
+func (mb *MessageBus) ListenUnix(path string) (*UnixServer, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &UnixServer{
+		bus:      mb,
+		path:     path,
+		listener: listener,
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    make(map[*net.UnixConn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	logger.InfoCF("bus", "Unix socket server listening", map[string]any{"path": path})
+	return s, nil
+}
+
+// Addr returns the socket path the server listens on.
+func (s *UnixServer) Addr() string {
+	return s.path
+}
+
+// Close stops accepting clients, disconnects the connected ones and removes
+// the socket file.
+func (s *UnixServer) Close() error {
+	s.cancel()
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (s *UnixServer) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.AcceptUnix()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				logger.ErrorCF("bus", "Unix socket accept failed", map[string]any{"error": err.Error()})
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.ctx.Err() != nil {
+			// Close already disconnected the other clients.
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+// serve pumps inbound messages to conn and publishes the outbound messages
+// conn sends back, until either side goes away.
+func (s *UnixServer) serve(conn *net.UnixConn) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer func() {
+		cancel()
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		s.writeInbound(ctx, conn)
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSocketLineSize)
+	for scanner.Scan() {
+		var msg OutboundMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			logger.WarnCF("bus", "Ignoring malformed outbound message", map[string]any{"error": err.Error()})
+			continue
+		}
+		if err := s.bus.PublishOutbound(ctx, msg); err != nil {
+			return
+		}
+	}
+}
+
+func (s *UnixServer) writeInbound(ctx context.Context, conn *net.UnixConn) {
+	enc := json.NewEncoder(conn)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-s.bus.inbound:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(msg); err != nil {
+				logger.WarnCF("bus", "Dropped inbound message, socket client write failed", map[string]any{
+					"channel": msg.Channel,
+					"chat_id": msg.ChatID,
+					"error":   err.Error(),
+				})
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package bus
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const echoSocketEnv = "PICOCLAW_BUS_ECHO_SOCKET"
+
+// TestMain lets the test binary double as the echo subprocess: when started
+// with echoSocketEnv set, it connects to that socket and answers every
+// inbound message with an outbound message carrying the same content.
+func TestMain(m *testing.M) {
+	if path := os.Getenv(echoSocketEnv); path != "" {
+		os.Exit(runEchoClient(path))
+	}
+	os.Exit(m.Run())
+}
+
+func runEchoClient(path string) int {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return 1
+	}
+	defer conn.Close()
+
+	enc := json.NewEncoder(conn)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var in InboundMessage
+		if err := json.Unmarshal(scanner.Bytes(), &in); err != nil {
+			return 1
+		}
+		if err := enc.Encode(OutboundMessage{Channel: in.Channel, ChatID: in.ChatID, Content: in.Content}); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+func shortSocketPath(t *testing.T) string {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "pcbus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "bus.sock")
+}
+
+func TestUnixServerRoundTrip(t *testing.T) {
+	mb := NewMessageBus()
+	path := shortSocketPath(t)
+	srv, err := mb.ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix: %v", err)
+	}
+
+	echo := exec.Command(os.Args[0], "-test.run=^$")
+	echo.Env = append(os.Environ(), echoSocketEnv+"="+path)
+	if err := echo.Start(); err != nil {
+		t.Fatalf("start echo process: %v", err)
+	}
+
+	const rounds = 200
+	latencies := make([]time.Duration, 0, rounds)
+	for i := range rounds {
+		content := "msg-" + strconv.Itoa(i)
+		start := time.Now()
+		msg := InboundMessage{Channel: "test", ChatID: "c1", Content: content}
+		if err := mb.PublishInbound(t.Context(), msg); err != nil {
+			t.Fatalf("PublishInbound: %v", err)
+		}
+		out, ok := mb.SubscribeOutbound(ctxWithTimeout(t, 5*time.Second))
+		if !ok {
+			t.Fatalf("round %d: no reply from echo process", i)
+		}
+		latencies = append(latencies, time.Since(start))
+		if out.Channel != "test" || out.ChatID != "c1" || out.Content != content {
+			t.Fatalf("round %d: reply = %+v", i, out)
+		}
+	}
+
+	// The first rounds include the client connecting; judge the steady state.
+	slices.Sort(latencies)
+	if median := latencies[rounds/2]; median > time.Millisecond {
+		t.Errorf("median round trip = %v, want under 1ms", median)
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if err := echo.Wait(); err != nil {
+		t.Errorf("echo process: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket file still present after Close: %v", err)
+	}
+}
+
+func TestUnixServerIgnoresMalformedLines(t *testing.T) {
+	mb := NewMessageBus()
+	path := shortSocketPath(t)
+	srv, err := mb.ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix: %v", err)
+	}
+	defer srv.Close()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("not json\n{\"channel\":\"test\",\"chat_id\":\"c\",\"content\":\"ok\"}\n"))
+
+	out, ok := mb.SubscribeOutbound(ctxWithTimeout(t, 5*time.Second))
+	if !ok || out.Content != "ok" {
+		t.Fatalf("SubscribeOutbound = %+v, %v", out, ok)
+	}
+}
+
+func TestListenUnixReplacesStaleSocket(t *testing.T) {
+	path := shortSocketPath(t)
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keep the file around as a crashed process would.
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	srv, err := NewMessageBus().ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix over stale socket: %v", err)
+	}
+	srv.Close()
+}
@@ -0,0 +1,227 @@
+// Package channelmanager supervises chat channels and restarts the ones that
+// stop running on their own.
+package channelmanager
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Channel is the part of a chat channel the manager drives. IsRunning is
+// polled from the supervisor goroutine, so it must be safe to call
+// concurrently with the channel's own goroutines.
+type Channel interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	IsRunning() bool
+}
+
+// State is the lifecycle state of a supervised channel.
+type State string
+
+const (
+	StateStarting   State = "starting"
+	StateRunning    State = "running"
+	StateRestarting State = "restarting"
+	StateFailed     State = "failed"
+	StateStopped    State = "stopped"
+)
+
+// ChannelStatus is a snapshot of one supervised channel.
+type ChannelStatus struct {
+	State     State     `json:"state"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+// Config controls restart behavior.
+type Config struct {
+	// RestartDelay is the pause between a channel going down and the next Start.
+	RestartDelay time.Duration
+	// MaxRestarts is how many failures are tolerated within RestartWindow
+	// before the manager gives up on a channel.
+	MaxRestarts   int
+	RestartWindow time.Duration
+	// PollInterval is how often IsRunning is checked while a channel is up.
+	PollInterval time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		RestartDelay:  5 * time.Second,
+		MaxRestarts:   5,
+		RestartWindow: 5 * time.Minute,
+		PollInterval:  time.Second,
+	}
+}
+
+type ChannelManager struct {
+	config   Config
+	channels map[string]Channel
+	status   map[string]*ChannelStatus
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.RWMutex
+
+	// onTransition, when set, is called after every state change.
+	onTransition func(name string, status ChannelStatus)
+}
+
+func NewChannelManager(cfg Config) *ChannelManager {
+	return &ChannelManager{
+		config:   cfg,
+		channels: make(map[string]Channel),
+		status:   make(map[string]*ChannelStatus),
+	}
+}
+
+// Register adds a channel to be supervised by the next Start.
+func (m *ChannelManager) Register(name string, ch Channel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channels[name] = ch
+	m.status[name] = &ChannelStatus{State: StateStopped, Since: time.Now()}
+}
+
+// Start launches one supervisor goroutine per registered channel and returns
+// immediately. Each channel is started with a context that Stop cancels.
+func (m *ChannelManager) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	m.mu.Lock()
+	m.cancel = cancel
+	channels := maps.Clone(m.channels)
+	m.mu.Unlock()
+
+	for name, ch := range channels {
+		m.wg.Add(1)
+		go m.supervise(ctx, name, ch)
+	}
+}
+
+// Stop ends supervision and waits for the supervisors to exit. It does not
+// stop the channels themselves; callers do that afterwards so that the
+// shutdown is not mistaken for a crash.
+func (m *ChannelManager) Stop() {
+	m.mu.RLock()
+	cancel := m.cancel
+	m.mu.RUnlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	m.wg.Wait()
+}
+
+// Status returns a snapshot of every registered channel.
+func (m *ChannelManager) Status() map[string]ChannelStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make(map[string]ChannelStatus, len(m.status))
+	for name, st := range m.status {
+		out[name] = *st
+	}
+	return out
+}
+
+func (m *ChannelManager) supervise(ctx context.Context, name string, ch Channel) {
+	defer m.wg.Done()
+
+	var failures []time.Time
+	for {
+		m.transition(name, StateStarting, nil, false)
+		err := ch.Start(ctx)
+		if err == nil {
+			m.transition(name, StateRunning, nil, false)
+			m.waitUntilDown(ctx, ch)
+		}
+		if ctx.Err() != nil {
+			m.transition(name, StateStopped, nil, false)
+			return
+		}
+
+		// Release whatever the failed instance still holds (its context,
+		// servers, goroutines) before it is started again or given up on.
+		if stopErr := ch.Stop(ctx); stopErr != nil {
+			logger.WarnCF("channelmanager", "Failed to stop channel before restart", map[string]any{
+				"channel": name,
+				"error":   stopErr.Error(),
+			})
+		}
+
+		now := time.Now()
+		failures = append(failures, now)
+		for len(failures) > 0 && now.Sub(failures[0]) > m.config.RestartWindow {
+			failures = failures[1:]
+		}
+		if len(failures) > m.config.MaxRestarts {
+			logger.ErrorCF("channelmanager", "Channel keeps failing, giving up", map[string]any{
+				"channel":  name,
+				"failures": len(failures),
+				"window":   m.config.RestartWindow.String(),
+			})
+			m.transition(name, StateFailed, err, false)
+			return
+		}
+
+		fields := map[string]any{
+			"channel": name,
+			"delay":   m.config.RestartDelay.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		logger.WarnCF("channelmanager", "Channel is down, restarting", fields)
+		m.transition(name, StateRestarting, err, true)
+
+		select {
+		case <-ctx.Done():
+			m.transition(name, StateStopped, nil, false)
+			return
+		case <-time.After(m.config.RestartDelay):
+		}
+	}
+}
+
+// waitUntilDown blocks until ch reports it is no longer running or ctx is done.
+func (m *ChannelManager) waitUntilDown(ctx context.Context, ch Channel) {
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !ch.IsRunning() {
+				return
+			}
+		}
+	}
+}
+
+func (m *ChannelManager) transition(name string, state State, err error, restart bool) {
+	m.mu.Lock()
+	st := m.status[name]
+	st.State = state
+	st.Since = time.Now()
+	if restart {
+		st.Restarts++
+	}
+	if err != nil {
+		st.LastError = err.Error()
+	}
+	snapshot := *st
+	notify := m.onTransition
+	m.mu.Unlock()
+
+	if notify != nil {
+		notify(name, snapshot)
+	}
+}
@@ -0,0 +1,171 @@
+package channelmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyChannel stops running shortly after each of its first `crashes`
+// starts; every later start stays up.
+type flakyChannel struct {
+	crashes int32
+	starts  atomic.Int32
+	stops   atomic.Int32
+	running atomic.Bool
+	// leaked counts starts not matched by a Stop before the next start.
+	leaked atomic.Int32
+}
+
+func (c *flakyChannel) Start(ctx context.Context) error {
+	if c.starts.Load() > c.stops.Load() {
+		c.leaked.Add(1)
+	}
+	n := c.starts.Add(1)
+	c.running.Store(true)
+	if n <= c.crashes {
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			c.running.Store(false)
+		}()
+	}
+	return nil
+}
+
+func (c *flakyChannel) Stop(ctx context.Context) error {
+	c.stops.Add(1)
+	c.running.Store(false)
+	return nil
+}
+
+func (c *flakyChannel) IsRunning() bool { return c.running.Load() }
+
+type brokenChannel struct{}
+
+func (brokenChannel) Start(ctx context.Context) error { return errors.New("bad token") }
+func (brokenChannel) Stop(ctx context.Context) error  { return nil }
+func (brokenChannel) IsRunning() bool                 { return false }
+
+func testConfig() Config {
+	return Config{
+		RestartDelay:  time.Millisecond,
+		MaxRestarts:   3,
+		RestartWindow: time.Minute,
+		PollInterval:  time.Millisecond,
+	}
+}
+
+type transitionLog struct {
+	mu     sync.Mutex
+	states []State
+	ch     chan ChannelStatus
+}
+
+func recordTransitions(m *ChannelManager) *transitionLog {
+	log := &transitionLog{ch: make(chan ChannelStatus, 64)}
+	m.onTransition = func(name string, st ChannelStatus) {
+		log.mu.Lock()
+		log.states = append(log.states, st.State)
+		log.mu.Unlock()
+		log.ch <- st
+	}
+	return log
+}
+
+func (l *transitionLog) waitFor(t *testing.T, want func(ChannelStatus) bool) ChannelStatus {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case st := <-l.ch:
+			if want(st) {
+				return st
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for status")
+		}
+	}
+}
+
+func TestChannelManagerRestartsUntilRecovered(t *testing.T) {
+	m := NewChannelManager(testConfig())
+	log := recordTransitions(m)
+	ch := &flakyChannel{crashes: 2}
+	m.Register("flaky", ch)
+
+	m.Start(t.Context())
+	log.waitFor(t, func(st ChannelStatus) bool { return st.State == StateRunning && st.Restarts == 2 })
+
+	// It must stay up once recovered.
+	time.Sleep(20 * time.Millisecond)
+	if st := m.Status()["flaky"]; st.State != StateRunning || st.Restarts != 2 {
+		t.Fatalf("status = %+v, want running with 2 restarts", st)
+	}
+	if got := ch.starts.Load(); got != 3 {
+		t.Fatalf("Start called %d times, want 3", got)
+	}
+	if got := ch.stops.Load(); got != 2 {
+		t.Fatalf("Stop called %d times, want 2 (once per restart)", got)
+	}
+	if got := ch.leaked.Load(); got != 0 {
+		t.Fatalf("%d restarts happened without stopping the previous instance", got)
+	}
+
+	m.Stop()
+	if st := m.Status()["flaky"]; st.State != StateStopped {
+		t.Fatalf("state after Stop = %s, want stopped", st.State)
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	want := []State{
+		StateStarting, StateRunning, StateRestarting,
+		StateStarting, StateRunning, StateRestarting,
+		StateStarting, StateRunning,
+		StateStopped,
+	}
+	if len(log.states) != len(want) {
+		t.Fatalf("transitions = %v, want %v", log.states, want)
+	}
+	for i := range want {
+		if log.states[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", log.states, want)
+		}
+	}
+}
+
+func TestChannelManagerGivesUpAfterMaxRestarts(t *testing.T) {
+	m := NewChannelManager(testConfig())
+	log := recordTransitions(m)
+	m.Register("broken", brokenChannel{})
+
+	m.Start(t.Context())
+	st := log.waitFor(t, func(st ChannelStatus) bool { return st.State == StateFailed })
+	m.Stop()
+
+	if st.Restarts != 3 {
+		t.Fatalf("restarts = %d, want 3", st.Restarts)
+	}
+	if st.LastError != "bad token" {
+		t.Fatalf("last error = %q, want %q", st.LastError, "bad token")
+	}
+}
+
+func TestChannelManagerStopDoesNotRestart(t *testing.T) {
+	m := NewChannelManager(testConfig())
+	ch := &flakyChannel{}
+	m.Register("steady", ch)
+
+	m.Start(t.Context())
+	time.Sleep(10 * time.Millisecond)
+	m.Stop()
+	ch.running.Store(false)
+	time.Sleep(10 * time.Millisecond)
+
+	if got := ch.starts.Load(); got != 1 {
+		t.Fatalf("Start called %d times after Stop, want 1", got)
+	}
+}
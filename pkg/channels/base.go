@@ -3,8 +3,11 @@ package channels
 import (
 	"context"
 	"strings"
+	"sync/atomic"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/plugin"
 )
 
 type Channel interface {
@@ -18,33 +21,89 @@ type Channel interface {
 
 type BaseChannel struct {
 	config    any
-	bus       *bus.MessageBus
-	running   bool
+	bus       bus.Publisher
+	running   atomic.Bool
 	name      string
-	allowList []string
+	allowList senderSet
+	blockList senderSet
+	plugins   *plugin.Registry
 }
 
-func NewBaseChannel(name string, config any, bus *bus.MessageBus, allowList []string) *BaseChannel {
+func NewBaseChannel(name string, config any, bus bus.Publisher, allowList, blockList []string) *BaseChannel {
 	return &BaseChannel{
 		config:    config,
 		bus:       bus,
 		name:      name,
-		allowList: allowList,
-		running:   false,
+		allowList: newSenderSet(allowList),
+		blockList: newSenderSet(blockList),
 	}
 }
 
+// SetPluginRegistry installs the plugin hooks applied to inbound messages.
+func (c *BaseChannel) SetPluginRegistry(reg *plugin.Registry) {
+	c.plugins = reg
+}
+
 func (c *BaseChannel) Name() string {
 	return c.name
 }
 
 func (c *BaseChannel) IsRunning() bool {
-	return c.running
+	return c.running.Load()
 }
 
+// IsAllowed reports whether senderID may talk to the bot. An empty allowlist
+// allows everyone; the blocklist is checked afterwards and always wins.
 func (c *BaseChannel) IsAllowed(senderID string) bool {
-	if len(c.allowList) == 0 {
-		return true
+	if !c.allowList.empty() && !c.allowList.matches(senderID) {
+		return false
+	}
+	return !c.blockList.matches(senderID)
+}
+
+// senderSet is a pre-indexed allow/block list. Entries are expanded once at
+// construction so that lookups are O(1) regardless of list size.
+type senderSet struct {
+	names map[string]struct{} // raw entries and their "@"-stripped form
+	ids   map[string]struct{} // id half of "id|username" entries
+	users map[string]struct{} // username half of "id|username" entries
+}
+
+func newSenderSet(entries []string) senderSet {
+	if len(entries) == 0 {
+		return senderSet{}
+	}
+
+	s := senderSet{
+		names: make(map[string]struct{}, len(entries)*2),
+		ids:   make(map[string]struct{}, len(entries)),
+		users: make(map[string]struct{}),
+	}
+	for _, entry := range entries {
+		// Strip leading "@" from allowed value for username matching
+		trimmed := strings.TrimPrefix(entry, "@")
+		s.names[entry] = struct{}{}
+		s.names[trimmed] = struct{}{}
+
+		id := trimmed
+		if idx := strings.Index(trimmed, "|"); idx > 0 {
+			id = trimmed[:idx]
+			if user := trimmed[idx+1:]; user != "" {
+				s.users[user] = struct{}{}
+			}
+		}
+		s.ids[id] = struct{}{}
+	}
+	return s
+}
+
+func (s senderSet) empty() bool {
+	return len(s.names) == 0
+}
+
+func (s senderSet) matches(senderID string) bool {
+	if s.empty() {
+		return false
 	}
 
 	// Extract parts from compound senderID like "123456|username"
@@ -55,37 +114,53 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 		userPart = senderID[idx+1:]
 	}
 
-	for _, allowed := range c.allowList {
-		// Strip leading "@" from allowed value for username matching
-		trimmed := strings.TrimPrefix(allowed, "@")
-		allowedID := trimmed
-		allowedUser := ""
-		if idx := strings.Index(trimmed, "|"); idx > 0 {
-			allowedID = trimmed[:idx]
-			allowedUser = trimmed[idx+1:]
-		}
-
-		// Support either side using "id|username" compound form.
-		// This keeps backward compatibility with legacy Telegram allowlist entries.
-		if senderID == allowed ||
-			idPart == allowed ||
-			senderID == trimmed ||
-			idPart == trimmed ||
-			idPart == allowedID ||
-			(allowedUser != "" && senderID == allowedUser) ||
-			(userPart != "" && (userPart == allowed || userPart == trimmed || userPart == allowedUser)) {
-			return true
-		}
+	// Support either side using "id|username" compound form.
+	// This keeps backward compatibility with legacy Telegram allowlist entries.
+	if s.hasName(senderID) || s.hasName(idPart) || s.hasID(idPart) || s.hasUser(senderID) {
+		return true
 	}
+	return userPart != "" && (s.hasName(userPart) || s.hasUser(userPart))
+}
+
+func (s senderSet) hasName(v string) bool {
+	_, ok := s.names[v]
+	return ok
+}
+
+func (s senderSet) hasID(v string) bool {
+	_, ok := s.ids[v]
+	return ok
+}
 
-	return false
+func (s senderSet) hasUser(v string) bool {
+	_, ok := s.users[v]
+	return ok
 }
 
-func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []string, metadata map[string]string) {
+func (c *BaseChannel) HandleMessage(
+	ctx context.Context,
+	senderID, chatID, content string,
+	media []string,
+	metadata map[string]string,
+) {
 	if !c.IsAllowed(senderID) {
 		return
 	}
 
+	if !c.plugins.Allow(content) {
+		logger.DebugCF("channels", "Message dropped by plugin filter", map[string]any{
+			"channel": c.name,
+			"chat_id": chatID,
+		})
+		return
+	}
+	content = c.plugins.Format(senderID, content)
+
+	traceID := bus.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = bus.NewTraceID()
+	}
+
 	msg := bus.InboundMessage{
 		Channel:  c.name,
 		SenderID: senderID,
@@ -93,11 +168,19 @@ func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []st
 		Content:  content,
 		Media:    media,
 		Metadata: metadata,
+		TraceID:  traceID,
 	}
 
-	c.bus.PublishInbound(msg)
+	if err := c.bus.PublishInbound(ctx, msg); err != nil {
+		logger.ErrorCF("channels", "Failed to publish inbound message", map[string]any{
+			"channel":  c.name,
+			"chat_id":  chatID,
+			"trace_id": traceID,
+			"error":    err.Error(),
+		})
+	}
 }
 
 func (c *BaseChannel) setRunning(running bool) {
-	c.running = running
+	c.running.Store(running)
 }
@@ -1,6 +1,13 @@
 package channels
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/plugin"
+)
 
 func TestBaseChannelIsAllowed(t *testing.T) {
 	tests := []struct {
@@ -43,10 +50,169 @@ func TestBaseChannelIsAllowed(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ch := NewBaseChannel("test", nil, nil, tt.allowList)
+			ch := NewBaseChannel("test", nil, nil, tt.allowList, nil)
 			if got := ch.IsAllowed(tt.senderID); got != tt.want {
 				t.Fatalf("IsAllowed(%q) = %v, want %v", tt.senderID, got, tt.want)
 			}
 		})
 	}
 }
+
+func TestBaseChannelBlockFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowList []string
+		blockList []string
+		senderID  string
+		want      bool
+	}{
+		{
+			name:      "blocked sender with empty allowlist",
+			blockList: []string{"spammer"},
+			senderID:  "spammer",
+			want:      false,
+		},
+		{
+			name:      "unblocked sender with empty allowlist",
+			blockList: []string{"spammer"},
+			senderID:  "friend",
+			want:      true,
+		},
+		{
+			name:      "block wins over allow",
+			allowList: []string{"123456"},
+			blockList: []string{"123456"},
+			senderID:  "123456",
+			want:      false,
+		},
+		{
+			name:      "block by username matches compound sender",
+			allowList: []string{"123456"},
+			blockList: []string{"@alice"},
+			senderID:  "123456|alice",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := NewBaseChannel("test", nil, nil, tt.allowList, tt.blockList)
+			if got := ch.IsAllowed(tt.senderID); got != tt.want {
+				t.Fatalf("IsAllowed(%q) = %v, want %v", tt.senderID, got, tt.want)
+			}
+		})
+	}
+}
+
+// isAllowedLinear is the original slice-scanning matcher, kept as a reference
+// for equivalence checks and benchmarks.
+func isAllowedLinear(allowList []string, senderID string) bool {
+	if len(allowList) == 0 {
+		return true
+	}
+
+	idPart := senderID
+	userPart := ""
+	if idx := strings.Index(senderID, "|"); idx > 0 {
+		idPart = senderID[:idx]
+		userPart = senderID[idx+1:]
+	}
+
+	for _, allowed := range allowList {
+		trimmed := strings.TrimPrefix(allowed, "@")
+		allowedID := trimmed
+		allowedUser := ""
+		if idx := strings.Index(trimmed, "|"); idx > 0 {
+			allowedID = trimmed[:idx]
+			allowedUser = trimmed[idx+1:]
+		}
+
+		if senderID == allowed ||
+			idPart == allowed ||
+			senderID == trimmed ||
+			idPart == trimmed ||
+			idPart == allowedID ||
+			(allowedUser != "" && senderID == allowedUser) ||
+			(userPart != "" && (userPart == allowed || userPart == trimmed || userPart == allowedUser)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestBaseChannelIsAllowedMatchesLinear(t *testing.T) {
+	allowList := []string{"123456", "@alice", "789|bob", "@42|carol", "dave"}
+	senders := []string{
+		"123456", "123456|x", "alice", "@alice", "9|alice", "789", "bob", "1|bob",
+		"42", "carol", "dave", "dave|z", "eve", "789|eve", "", "|", "x|",
+	}
+
+	ch := NewBaseChannel("test", nil, nil, allowList, nil)
+	for _, sender := range senders {
+		if got, want := ch.IsAllowed(sender), isAllowedLinear(allowList, sender); got != want {
+			t.Errorf("IsAllowed(%q) = %v, linear reference = %v", sender, got, want)
+		}
+	}
+}
+
+func BenchmarkIsAllowed(b *testing.B) {
+	for _, size := range []int{10, 100, 1000, 10000} {
+		allowList := make([]string, size)
+		for i := range allowList {
+			allowList[i] = fmt.Sprintf("UC%08d", i)
+		}
+		// Worst case for the linear scan: a sender that is not on the list.
+		sender := "UCmissing"
+
+		b.Run(fmt.Sprintf("slice/%d", size), func(b *testing.B) {
+			for b.Loop() {
+				isAllowedLinear(allowList, sender)
+			}
+		})
+
+		ch := NewBaseChannel("test", nil, nil, allowList, nil)
+		b.Run(fmt.Sprintf("map/%d", size), func(b *testing.B) {
+			for b.Loop() {
+				ch.IsAllowed(sender)
+			}
+		})
+	}
+}
+
+func TestBaseChannelHandleMessageSetsTraceID(t *testing.T) {
+	mb := bus.NewMockBus()
+	ch := NewBaseChannel("test", nil, mb, nil, nil)
+
+	ch.HandleMessage(t.Context(), "u1", "c1", "hello", nil, nil)
+	ch.HandleMessage(bus.WithTraceID(t.Context(), "upstream"), "u1", "c1", "again", nil, nil)
+
+	msgs := mb.InboundMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("published %d messages, want 2", len(msgs))
+	}
+	if msgs[0].TraceID == "" {
+		t.Error("HandleMessage should assign a trace ID")
+	}
+	if msgs[1].TraceID != "upstream" {
+		t.Errorf("TraceID = %q, want the one carried by ctx", msgs[1].TraceID)
+	}
+}
+
+func TestBaseChannelHandleMessageAppliesPlugins(t *testing.T) {
+	reg := plugin.NewRegistry()
+	reg.AddPreFilter(func(text string) bool { return !strings.Contains(text, "spam") })
+	reg.AddFormatter(func(author, text string) string { return author + " says " + text })
+
+	mb := bus.NewMockBus()
+	ch := NewBaseChannel("test", nil, mb, nil, nil)
+	ch.SetPluginRegistry(reg)
+
+	ch.HandleMessage(t.Context(), "alice", "c1", "buy spam", nil, nil)
+	ch.HandleMessage(t.Context(), "alice", "c1", "hello", nil, nil)
+
+	msgs := mb.InboundMessages()
+	if len(msgs) != 1 || msgs[0].Content != "alice says hello" {
+		t.Errorf("published %+v, want only the formatted second message", msgs)
+	}
+}
@@ -0,0 +1,87 @@
+package channels
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// ConformanceOptions tunes ChannelConformanceTest for one channel.
+type ConformanceOptions struct {
+	// Message is passed to Send before Start. The zero value is used if unset.
+	Message bus.OutboundMessage
+	// SettleTimeout bounds how long the goroutine check waits for a stopped
+	// channel's goroutines to exit. Defaults to two seconds.
+	SettleTimeout time.Duration
+}
+
+// ChannelConformanceTest checks the lifecycle behaviour every Channel must
+// have. newChannel is called once per sub-test and must return a fresh
+// channel that can be started without reaching a real service, for example
+// by pointing it at an unreachable or httptest URL.
+func ChannelConformanceTest(t *testing.T, newChannel func() Channel, opts ConformanceOptions) {
+	t.Helper()
+	if opts.SettleTimeout <= 0 {
+		opts.SettleTimeout = 2 * time.Second
+	}
+	ctx := context.Background()
+
+	t.Run("Name", func(t *testing.T) {
+		if newChannel().Name() == "" {
+			t.Error("Name() is empty")
+		}
+	})
+
+	t.Run("StartStop", func(t *testing.T) {
+		ch := newChannel()
+		if ch.IsRunning() {
+			t.Error("IsRunning() = true before Start")
+		}
+		if err := ch.Start(ctx); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		if !ch.IsRunning() {
+			t.Error("IsRunning() = false after Start")
+		}
+		if err := ch.Stop(ctx); err != nil {
+			t.Errorf("Stop: %v", err)
+		}
+		if ch.IsRunning() {
+			t.Error("IsRunning() = true after Stop")
+		}
+	})
+
+	t.Run("StopWithoutStart", func(t *testing.T) {
+		_ = newChannel().Stop(ctx)
+	})
+
+	t.Run("SendBeforeStart", func(t *testing.T) {
+		_ = newChannel().Send(ctx, opts.Message)
+	})
+
+	t.Run("DoubleStartDoesNotLeak", func(t *testing.T) {
+		ch := newChannel()
+		before := runtime.NumGoroutine()
+
+		if err := ch.Start(ctx); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		if err := ch.Start(ctx); err != nil {
+			t.Fatalf("second Start: %v", err)
+		}
+		if err := ch.Stop(ctx); err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+
+		deadline := time.Now().Add(opts.SettleTimeout)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Fatalf("goroutines = %d after Stop, want at most %d", runtime.NumGoroutine(), before)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
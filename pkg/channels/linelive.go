@@ -0,0 +1,220 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	lineLiveEventsURL  = "wss://linelive.line.me/comments/v1/events"
+	lineLiveRetryDelay = 5 * time.Second
+)
+
+// LineLiveChannel reads chat comments from a LINE LIVE broadcast over the
+// comments WebSocket. It does not post comments, so outbound messages
+// addressed to it are dropped.
+type LineLiveChannel struct {
+	*BaseChannel
+	config     config.LineLiveConfig
+	wsURL      string
+	retryDelay time.Duration
+	ngWords    ngWordFilter
+	log        *logger.Logger
+	conn       *websocket.Conn
+	mu         sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// lineLiveEvent is one event frame. Frames may arrive as text or binary
+// messages; both carry the same JSON.
+type lineLiveEvent struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Author  struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+func NewLineLiveChannel(cfg config.LineLiveConfig, messageBus bus.Publisher) (*LineLiveChannel, error) {
+	if cfg.BroadcastID == "" || cfg.AccessToken == "" {
+		return nil, fmt.Errorf("linelive broadcast_id and access_token are required")
+	}
+
+	base := NewBaseChannel("linelive", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
+
+	return &LineLiveChannel{
+		BaseChannel: base,
+		config:      cfg,
+		wsURL:       lineLiveEventsURL,
+		retryDelay:  lineLiveRetryDelay,
+		ngWords:     newNGWordFilter(cfg.NGWords),
+		log:         logger.WithC("linelive", map[string]any{"broadcast_id": cfg.BroadcastID}),
+	}, nil
+}
+
+func (c *LineLiveChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
+	c.log.Info("Starting LINE LIVE channel")
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	go c.run()
+
+	c.setRunning(true)
+	c.log.Info("LINE LIVE channel started")
+	return nil
+}
+
+func (c *LineLiveChannel) Stop(ctx context.Context) error {
+	c.log.Info("Stopping LINE LIVE channel")
+	c.setRunning(false)
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *LineLiveChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.log.DebugF("Dropping outbound message for receive-only channel", map[string]any{
+		"chat_id": msg.ChatID,
+	})
+	return nil
+}
+
+// run keeps a connection open until the channel is stopped, waiting
+// retryDelay after each disconnect before dialing again.
+func (c *LineLiveChannel) run() {
+	for {
+		err := c.session()
+		if c.ctx.Err() != nil {
+			return
+		}
+
+		c.log.SampledWarnF(c.retryLogKey(), "Connection ended, retrying", map[string]any{
+			"error": fmt.Sprint(err),
+			"delay": c.retryDelay.String(),
+		})
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// session reads one WebSocket connection until it fails. WebSocket pings are
+// answered by the default pong handler.
+func (c *LineLiveChannel) session() error {
+	endpoint := c.wsURL + "?" + url.Values{"broadcast_id": {c.config.BroadcastID}}.Encode()
+	header := http.Header{"Authorization": {"Bearer " + c.config.AccessToken}}
+
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+	conn, resp, err := dialer.DialContext(c.ctx, endpoint, header)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", c.wsURL, err)
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.mu.Unlock()
+		conn.Close()
+	}()
+
+	c.log.Info("Connected to LINE LIVE comments")
+	logger.ResetSampled(c.retryLogKey())
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var event lineLiveEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			c.log.WarnF("Failed to parse event", map[string]any{"error": err.Error()})
+			continue
+		}
+
+		switch event.Type {
+		case "chat":
+			c.handleChat(event)
+		default:
+			c.log.DebugF("Ignoring event", map[string]any{"type": event.Type})
+		}
+	}
+}
+
+// retryLogKey samples the retry warning, which repeats while LINE LIVE is
+// unreachable.
+func (c *LineLiveChannel) retryLogKey() string {
+	return "linelive:" + c.config.BroadcastID + ":session"
+}
+
+func (c *LineLiveChannel) handleChat(event lineLiveEvent) {
+	if event.Message == "" || event.Author.ID == "" {
+		return
+	}
+	if word, ok := c.ngWords.match(event.Message); ok {
+		c.log.DebugF("Dropping comment containing NG word", map[string]any{
+			"comment_id": event.ID,
+			"word":       word,
+		})
+		return
+	}
+
+	content := c.formatChat(event)
+	c.log.InfoF("Received comment", map[string]any{
+		"user_id": event.Author.ID,
+		"preview": utils.Truncate(content, 50),
+	})
+
+	metadata := map[string]string{
+		"comment_id":   event.ID,
+		"broadcast_id": c.config.BroadcastID,
+		"user_name":    event.Author.Name,
+	}
+	c.HandleMessage(c.ctx, event.Author.ID, c.config.BroadcastID, content, nil, metadata)
+}
+
+// formatChat fills the configured message format. Supported placeholders are
+// {message}, {user} and {user_id}.
+func (c *LineLiveChannel) formatChat(event lineLiveEvent) string {
+	return expandMessageFormat(c.config.MessageFormat, map[string]string{
+		"message": event.Message,
+		"user":    event.Author.Name,
+		"user_id": event.Author.ID,
+	})
+}
@@ -0,0 +1,159 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func loadLineLiveFrames(t *testing.T) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "linelive", "frames.jsonl"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+}
+
+// lineLiveServer replays one list of binary frames per connection, closing
+// the connection after each list except the last, which it holds open.
+type lineLiveServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	sessions [][][]byte
+	requests []*http.Request
+}
+
+func newLineLiveServer(t *testing.T, sessions ...[][]byte) *lineLiveServer {
+	t.Helper()
+	s := &lineLiveServer{sessions: sessions}
+	upgrader := websocket.Upgrader{}
+	done := make(chan struct{})
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		n := len(s.requests)
+		s.requests = append(s.requests, r)
+		s.mu.Unlock()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if n >= len(s.sessions) {
+			<-done
+			return
+		}
+		for _, frame := range s.sessions[n] {
+			if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+				return
+			}
+		}
+		if n == len(s.sessions)-1 {
+			<-done
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		s.Server.Close()
+	})
+	return s
+}
+
+func (s *lineLiveServer) request(i int) *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[i]
+}
+
+func startTestLineLiveChannel(t *testing.T, srv *lineLiveServer, cfg config.LineLiveConfig) *bus.MockMessageBus {
+	t.Helper()
+	cfg.BroadcastID = "b42"
+	cfg.AccessToken = "tok"
+	msgBus := bus.NewMockBus()
+	ch, err := NewLineLiveChannel(cfg, msgBus)
+	if err != nil {
+		t.Fatalf("NewLineLiveChannel: %v", err)
+	}
+	ch.wsURL = wsURL(srv.Server)
+	ch.retryDelay = 10 * time.Millisecond
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+	return msgBus
+}
+
+func TestNewLineLiveChannelRequiresCredentials(t *testing.T) {
+	if _, err := NewLineLiveChannel(config.LineLiveConfig{AccessToken: "tok"}, bus.NewMockBus()); err == nil {
+		t.Error("expected error without broadcast_id")
+	}
+	if _, err := NewLineLiveChannel(config.LineLiveConfig{BroadcastID: "b42"}, bus.NewMockBus()); err == nil {
+		t.Error("expected error without access_token")
+	}
+}
+
+func TestLineLiveExtractsChatFromFrames(t *testing.T) {
+	srv := newLineLiveServer(t, loadLineLiveFrames(t))
+	msgBus := startTestLineLiveChannel(t, srv, config.LineLiveConfig{
+		MessageFormat: "{user}: {message}",
+		NGWords:       config.FlexibleStringSlice{"spam"},
+	})
+
+	msgs := msgBus.ExpectInbound(t, 3, 2*time.Second)
+	want := []struct{ sender, content, commentID string }{
+		{"u100", "Hanako: こんばんは！", "c1"},
+		{"u101", "Taro: 今日の配信楽しみ", "c2"},
+		{"u100", "Hanako: 歌ってほしい", "c4"},
+	}
+	for i, w := range want {
+		got := msgs[i]
+		if got.Channel != "linelive" || got.ChatID != "b42" || got.SenderID != w.sender || got.Content != w.content {
+			t.Errorf("message %d = %+v, want sender %q content %q", i, got, w.sender, w.content)
+		}
+		if got.Metadata["comment_id"] != w.commentID {
+			t.Errorf("message %d comment_id = %q, want %q", i, got.Metadata["comment_id"], w.commentID)
+		}
+	}
+
+	r := srv.request(0)
+	if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := r.URL.Query().Get("broadcast_id"); got != "b42" {
+		t.Errorf("broadcast_id = %q", got)
+	}
+}
+
+func TestLineLiveReconnectsAfterDisconnect(t *testing.T) {
+	frames := loadLineLiveFrames(t)
+	srv := newLineLiveServer(t, frames[:1], frames[2:3])
+	msgBus := startTestLineLiveChannel(t, srv, config.LineLiveConfig{})
+
+	msgs := msgBus.ExpectInbound(t, 2, 2*time.Second)
+	if msgs[0].Content != "こんばんは！" || msgs[1].Content != "今日の配信楽しみ" {
+		t.Errorf("contents = %q, %q", msgs[0].Content, msgs[1].Content)
+	}
+}
+
+func TestLineLiveChannelConformance(t *testing.T) {
+	ChannelConformanceTest(t, func() Channel {
+		ch, err := NewLineLiveChannel(config.LineLiveConfig{BroadcastID: "b42", AccessToken: "tok"}, bus.NewMockBus())
+		if err != nil {
+			t.Fatalf("NewLineLiveChannel: %v", err)
+		}
+		ch.wsURL = "ws://127.0.0.1:0"
+		return ch
+	}, ConformanceOptions{})
+}
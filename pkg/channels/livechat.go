@@ -0,0 +1,46 @@
+package channels
+
+import "strings"
+
+// Helpers shared by the live-stream chat channels (TwitCasting, LINE LIVE),
+// which drop comments containing configured NG words and render the rest
+// through a message format template.
+
+const defaultMessageFormat = "{message}"
+
+// ngWordFilter matches messages containing any of its words, ignoring case.
+type ngWordFilter []string
+
+func newNGWordFilter(words []string) ngWordFilter {
+	f := make(ngWordFilter, 0, len(words))
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f = append(f, w)
+		}
+	}
+	return f
+}
+
+// match reports the first NG word found in message.
+func (f ngWordFilter) match(message string) (string, bool) {
+	lower := strings.ToLower(message)
+	for _, w := range f {
+		if strings.Contains(lower, w) {
+			return w, true
+		}
+	}
+	return "", false
+}
+
+// expandMessageFormat replaces each {name} in format with values[name]. An
+// empty format means defaultMessageFormat.
+func expandMessageFormat(format string, values map[string]string) string {
+	if format == "" {
+		format = defaultMessageFormat
+	}
+	pairs := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(format)
+}
@@ -27,8 +27,8 @@ type MaixCamMessage struct {
 	Data      map[string]any `json:"data"`
 }
 
-func NewMaixCamChannel(cfg config.MaixCamConfig, bus *bus.MessageBus) (*MaixCamChannel, error) {
-	base := NewBaseChannel("maixcam", cfg, bus, cfg.AllowFrom)
+func NewMaixCamChannel(cfg config.MaixCamConfig, bus bus.Publisher) (*MaixCamChannel, error) {
+	base := NewBaseChannel("maixcam", cfg, bus, cfg.AllowFrom, cfg.BlockFrom)
 
 	return &MaixCamChannel{
 		BaseChannel: base,
@@ -70,7 +70,7 @@ func (c *MaixCamChannel) acceptConnections(ctx context.Context) {
 		default:
 			conn, err := c.listener.Accept()
 			if err != nil {
-				if c.running {
+				if c.IsRunning() {
 					logger.ErrorCF("maixcam", "Failed to accept connection", map[string]any{
 						"error": err.Error(),
 					})
@@ -119,15 +119,15 @@ func (c *MaixCamChannel) handleConnection(conn net.Conn, ctx context.Context) {
 				return
 			}
 
-			c.processMessage(msg, conn)
+			c.processMessage(ctx, msg, conn)
 		}
 	}
 }
 
-func (c *MaixCamChannel) processMessage(msg MaixCamMessage, conn net.Conn) {
+func (c *MaixCamChannel) processMessage(ctx context.Context, msg MaixCamMessage, conn net.Conn) {
 	switch msg.Type {
 	case "person_detected":
-		c.handlePersonDetection(msg)
+		c.handlePersonDetection(ctx, msg)
 	case "heartbeat":
 		logger.DebugC("maixcam", "Received heartbeat")
 	case "status":
@@ -139,7 +139,7 @@ func (c *MaixCamChannel) processMessage(msg MaixCamMessage, conn net.Conn) {
 	}
 }
 
-func (c *MaixCamChannel) handlePersonDetection(msg MaixCamMessage) {
+func (c *MaixCamChannel) handlePersonDetection(ctx context.Context, msg MaixCamMessage) {
 	logger.InfoCF("maixcam", "", map[string]any{
 		"timestamp": msg.Timestamp,
 		"data":      msg.Data,
@@ -174,7 +174,7 @@ func (c *MaixCamChannel) handlePersonDetection(msg MaixCamMessage) {
 		"peer_id":   "default",
 	}
 
-	c.HandleMessage(senderID, chatID, content, []string{}, metadata)
+	c.HandleMessage(ctx, senderID, chatID, content, []string{}, metadata)
 }
 
 func (c *MaixCamChannel) handleStatusUpdate(msg MaixCamMessage) {
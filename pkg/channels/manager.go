@@ -12,16 +12,20 @@ import (
 	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channelmanager"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/plugin"
+	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
 type Manager struct {
 	channels     map[string]Channel
-	bus          *bus.MessageBus
+	bus          bus.Bus
 	config       *config.Config
 	dispatchTask *asyncTask
+	supervisor   *channelmanager.ChannelManager
 	mu           sync.RWMutex
 }
 
@@ -29,7 +33,7 @@ type asyncTask struct {
 	cancel context.CancelFunc
 }
 
-func NewManager(cfg *config.Config, messageBus *bus.MessageBus) (*Manager, error) {
+func NewManager(cfg *config.Config, messageBus bus.Bus) (*Manager, error) {
 	m := &Manager{
 		channels: make(map[string]Channel),
 		bus:      messageBus,
@@ -40,9 +44,43 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus) (*Manager, error
 		return nil, err
 	}
 
+	if dir := cfg.Plugins.Dir; dir != "" {
+		m.loadPlugins(dir)
+	}
+
 	return m, nil
 }
 
+// loadPlugins loads the Go plugins in dir and hands their hooks to every
+// channel. Plugins that fail to load are logged and skipped.
+func (m *Manager) loadPlugins(dir string) {
+	if !plugin.Supported {
+		logger.WarnCF("channels", "plugins.dir is set, but this binary was built without plugin support "+
+			"(CGO_ENABLED=0); no plugins will be loaded. Rebuild with CGO_ENABLED=1 to use plugins.",
+			map[string]any{"dir": dir})
+		return
+	}
+
+	reg := plugin.NewRegistry()
+	loaded, err := plugin.NewPluginLoader(dir).Load(reg)
+	if err != nil {
+		logger.ErrorCF("channels", "Failed to load some plugins", map[string]any{
+			"dir":   dir,
+			"error": err.Error(),
+		})
+	}
+	if len(loaded) == 0 {
+		return
+	}
+
+	for _, ch := range m.channels {
+		if pc, ok := ch.(interface{ SetPluginRegistry(*plugin.Registry) }); ok {
+			pc.SetPluginRegistry(reg)
+		}
+	}
+	logger.InfoCF("channels", "Plugins enabled", map[string]any{"plugins": loaded})
+}
+
 func (m *Manager) initChannels() error {
 	logger.InfoC("channels", "Initializing channel manager")
 
@@ -202,6 +240,71 @@ func (m *Manager) initChannels() error {
 		}
 	}
 
+	if m.config.Channels.TwitchEventSub.Enabled && m.config.Channels.TwitchEventSub.ClientID != "" {
+		logger.DebugC("channels", "Attempting to initialize Twitch EventSub channel")
+		twitchEventSub, err := NewTwitchEventSubChannel(m.config.Channels.TwitchEventSub, m.bus)
+		if err != nil {
+			logger.ErrorCF("channels", "Failed to initialize Twitch EventSub channel", map[string]any{
+				"error": err.Error(),
+			})
+		} else {
+			m.channels["twitch_eventsub"] = twitchEventSub
+			logger.InfoC("channels", "Twitch EventSub channel enabled successfully")
+		}
+	}
+
+	if m.config.Channels.Twicas.Enabled && m.config.Channels.Twicas.AccessToken != "" {
+		logger.DebugC("channels", "Attempting to initialize TwitCasting channel")
+		twicas, err := NewTwicasChannel(m.config.Channels.Twicas, m.bus)
+		if err != nil {
+			logger.ErrorCF("channels", "Failed to initialize TwitCasting channel", map[string]any{
+				"error": err.Error(),
+			})
+		} else {
+			m.channels["twicas"] = twicas
+			logger.InfoC("channels", "TwitCasting channel enabled successfully")
+		}
+	}
+
+	if m.config.Channels.NicoNicoTimeshift.Enabled && m.config.Channels.NicoNicoTimeshift.UserSession != "" {
+		logger.DebugC("channels", "Attempting to initialize NicoNico timeshift channel")
+		timeshift, err := NewNicoNicoTimeshiftChannel(m.config.Channels.NicoNicoTimeshift, m.bus)
+		if err != nil {
+			logger.ErrorCF("channels", "Failed to initialize NicoNico timeshift channel", map[string]any{
+				"error": err.Error(),
+			})
+		} else {
+			m.channels["niconico_timeshift"] = timeshift
+			logger.InfoC("channels", "NicoNico timeshift channel enabled successfully")
+		}
+	}
+
+	if m.config.Channels.LineLive.Enabled && m.config.Channels.LineLive.AccessToken != "" {
+		logger.DebugC("channels", "Attempting to initialize LINE LIVE channel")
+		lineLive, err := NewLineLiveChannel(m.config.Channels.LineLive, m.bus)
+		if err != nil {
+			logger.ErrorCF("channels", "Failed to initialize LINE LIVE channel", map[string]any{
+				"error": err.Error(),
+			})
+		} else {
+			m.channels["linelive"] = lineLive
+			logger.InfoC("channels", "LINE LIVE channel enabled successfully")
+		}
+	}
+
+	if m.config.Channels.Notion.Enabled && m.config.Channels.Notion.IntegrationToken != "" {
+		logger.DebugC("channels", "Attempting to initialize Notion channel")
+		notion, err := NewNotionChannel(m.config.Channels.Notion, m.bus)
+		if err != nil {
+			logger.ErrorCF("channels", "Failed to initialize Notion channel", map[string]any{
+				"error": err.Error(),
+			})
+		} else {
+			m.channels["notion"] = notion
+			logger.InfoC("channels", "Notion channel enabled successfully")
+		}
+	}
+
 	logger.InfoCF("channels", "Channel initialization completed", map[string]any{
 		"enabled_channels": len(m.channels),
 	})
@@ -225,17 +328,15 @@ func (m *Manager) StartAll(ctx context.Context) error {
 
 	go m.dispatchOutbound(dispatchCtx)
 
+	// The supervisor starts each channel and restarts it if it stops running.
+	m.supervisor = channelmanager.NewChannelManager(channelmanager.DefaultConfig())
 	for name, channel := range m.channels {
 		logger.InfoCF("channels", "Starting channel", map[string]any{
 			"channel": name,
 		})
-		if err := channel.Start(ctx); err != nil {
-			logger.ErrorCF("channels", "Failed to start channel", map[string]any{
-				"channel": name,
-				"error":   err.Error(),
-			})
-		}
+		m.supervisor.Register(name, channel)
 	}
+	m.supervisor.Start(ctx)
 
 	logger.InfoC("channels", "All channels started")
 	return nil
@@ -252,6 +353,12 @@ func (m *Manager) StopAll(ctx context.Context) error {
 		m.dispatchTask = nil
 	}
 
+	// Stop supervising first so the shutdown below is not treated as a crash.
+	if m.supervisor != nil {
+		m.supervisor.Stop()
+		m.supervisor = nil
+	}
+
 	for name, channel := range m.channels {
 		logger.InfoCF("channels", "Stopping channel", map[string]any{
 			"channel": name,
@@ -298,12 +405,20 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				continue
 			}
 
-			if err := channel.Send(ctx, msg); err != nil {
+			if err := channel.Send(bus.WithTraceID(ctx, msg.TraceID), msg); err != nil {
 				logger.ErrorCF("channels", "Error sending message to channel", map[string]any{
-					"channel": msg.Channel,
-					"error":   err.Error(),
+					"channel":  msg.Channel,
+					"trace_id": msg.TraceID,
+					"error":    err.Error(),
 				})
+				continue
 			}
+			logger.DebugCF("channels", "Message sent", map[string]any{
+				"channel":  msg.Channel,
+				"chat_id":  msg.ChatID,
+				"trace_id": msg.TraceID,
+				"preview":  utils.Truncate(msg.Content, 50),
+			})
 		}
 	}
 }
@@ -319,12 +434,22 @@ func (m *Manager) GetStatus() map[string]any {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	var supervised map[string]channelmanager.ChannelStatus
+	if m.supervisor != nil {
+		supervised = m.supervisor.Status()
+	}
+
 	status := make(map[string]any)
 	for name, channel := range m.channels {
-		status[name] = map[string]any{
+		entry := map[string]any{
 			"enabled": true,
 			"running": channel.IsRunning(),
 		}
+		if st, ok := supervised[name]; ok {
+			entry["state"] = st.State
+			entry["restarts"] = st.Restarts
+		}
+		status[name] = entry
 	}
 	return status
 }
@@ -0,0 +1,86 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// MuxChannel is an outbound-only channel that sends every message to several
+// other channels at once. It does not own the channels it wraps: Start and
+// Stop only change its own running state.
+type MuxChannel struct {
+	*BaseChannel
+	targets []Channel
+}
+
+// NewMuxChannel returns a channel called name that broadcasts to targets.
+func NewMuxChannel(name string, targets ...Channel) *MuxChannel {
+	return &MuxChannel{
+		BaseChannel: NewBaseChannel(name, nil, nil, nil, nil),
+		targets:     targets,
+	}
+}
+
+// ForwardTarget is a chat on another channel that messages are copied to.
+type ForwardTarget struct {
+	Channel string
+	ChatID  string
+}
+
+// NewForwardMux returns a MuxChannel that republishes each message to the
+// outbound bus once per target, addressed to that target's channel and chat.
+// Registered with the Manager, it lets one reply reach several chats.
+func NewForwardMux(name string, messageBus bus.Publisher, targets []ForwardTarget) *MuxChannel {
+	forwards := make([]Channel, 0, len(targets))
+	for _, target := range targets {
+		forwards = append(forwards, &forwardChannel{
+			BaseChannel: NewBaseChannel(target.Channel, nil, nil, nil, nil),
+			bus:         messageBus,
+			target:      target,
+		})
+	}
+	return NewMuxChannel(name, forwards...)
+}
+
+func (c *MuxChannel) Start(ctx context.Context) error {
+	c.setRunning(true)
+	return nil
+}
+
+func (c *MuxChannel) Stop(ctx context.Context) error {
+	c.setRunning(false)
+	return nil
+}
+
+// Send delivers msg to every target, even when some fail. The failures are
+// joined into the returned error; errors.Join's Unwrap() []error gives them
+// back individually.
+func (c *MuxChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	var errs []error
+	for _, target := range c.targets {
+		if err := target.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// forwardChannel readdresses outbound messages to a ForwardTarget.
+type forwardChannel struct {
+	*BaseChannel
+	bus    bus.Publisher
+	target ForwardTarget
+}
+
+func (c *forwardChannel) Start(ctx context.Context) error { return nil }
+
+func (c *forwardChannel) Stop(ctx context.Context) error { return nil }
+
+func (c *forwardChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	msg.Channel = c.target.Channel
+	msg.ChatID = c.target.ChatID
+	return c.bus.PublishOutbound(ctx, msg)
+}
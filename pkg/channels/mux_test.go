@@ -0,0 +1,84 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// recordingChannel is an outbound channel that remembers what it was sent.
+type recordingChannel struct {
+	*BaseChannel
+	sent []bus.OutboundMessage
+	err  error
+}
+
+func newRecordingChannel(name string, err error) *recordingChannel {
+	return &recordingChannel{BaseChannel: NewBaseChannel(name, nil, nil, nil, nil), err: err}
+}
+
+func (c *recordingChannel) Start(ctx context.Context) error { return nil }
+
+func (c *recordingChannel) Stop(ctx context.Context) error { return nil }
+
+func (c *recordingChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.sent = append(c.sent, msg)
+	return c.err
+}
+
+func TestForwardMuxPublishesToEveryTarget(t *testing.T) {
+	mb := bus.NewMockBus()
+	mux := NewForwardMux("forward", mb, []ForwardTarget{
+		{Channel: "telegram", ChatID: "123"},
+		{Channel: "discord", ChatID: "456"},
+	})
+
+	err := mux.Send(t.Context(), bus.OutboundMessage{Channel: "forward", ChatID: "ignored", Content: "hi", TraceID: "t1"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := mb.OutboundMessages()
+	if len(got) != 2 {
+		t.Fatalf("published %d messages, want 2: %+v", len(got), got)
+	}
+	want := []bus.OutboundMessage{
+		{Channel: "telegram", ChatID: "123", Content: "hi", TraceID: "t1"},
+		{Channel: "discord", ChatID: "456", Content: "hi", TraceID: "t1"},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMuxChannelCollectsErrors(t *testing.T) {
+	ok := newRecordingChannel("ok", nil)
+	bad := newRecordingChannel("bad", errors.New("boom"))
+	worse := newRecordingChannel("worse", errors.New("bang"))
+	mux := NewMuxChannel("mux", bad, ok, worse)
+
+	err := mux.Send(t.Context(), bus.OutboundMessage{Content: "hi"})
+	if err == nil {
+		t.Fatal("Send should report the failing targets")
+	}
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Error(), "bad: ") || !strings.HasPrefix(errs[1].Error(), "worse: ") {
+		t.Errorf("errors = %v", errs)
+	}
+	for _, ch := range []*recordingChannel{ok, bad, worse} {
+		if len(ch.sent) != 1 {
+			t.Errorf("%s received %d messages, want 1", ch.Name(), len(ch.sent))
+		}
+	}
+}
+
+func TestMuxChannelConformance(t *testing.T) {
+	ChannelConformanceTest(t, func() Channel {
+		return NewForwardMux("forward", bus.NewMockBus(), []ForwardTarget{{Channel: "telegram", ChatID: "1"}})
+	}, ConformanceOptions{})
+}
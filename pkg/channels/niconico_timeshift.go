@@ -0,0 +1,222 @@
+package channels
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	nicoMessageServerURL = "https://nmsg.nicovideo.jp"
+	nicoCommentHistory   = 1000
+	nicoVposUnit         = 10 * time.Millisecond
+)
+
+// NicoNicoTimeshiftChannel replays the archived comments of a NicoNico
+// timeshift. It fetches the comment XML once and publishes each comment when
+// playback reaches its vpos, counted from Start and scaled by PlaybackSpeed.
+// This makes recorded broadcasts usable for offline testing. It does not
+// post comments, so outbound messages addressed to it are dropped.
+type NicoNicoTimeshiftChannel struct {
+	*BaseChannel
+	config     config.TimeshiftConfig
+	httpClient *http.Client
+	apiURL     string
+	speed      float64
+	clock      timeshiftClock
+	log        *logger.Logger
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// timeshiftClock is the time source for playback, replaced in tests.
+type timeshiftClock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type nicoPacket struct {
+	Chats []nicoChat `xml:"chat"`
+}
+
+type nicoChat struct {
+	No      int    `xml:"no,attr"`
+	Vpos    int64  `xml:"vpos,attr"`
+	UserID  string `xml:"user_id,attr"`
+	Mail    string `xml:"mail,attr"`
+	Deleted int    `xml:"deleted,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func NewNicoNicoTimeshiftChannel(
+	cfg config.TimeshiftConfig,
+	messageBus bus.Publisher,
+) (*NicoNicoTimeshiftChannel, error) {
+	if cfg.ContentID == "" || cfg.UserSession == "" {
+		return nil, fmt.Errorf("niconico_timeshift content_id and user_session are required")
+	}
+
+	speed := cfg.PlaybackSpeed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	base := NewBaseChannel("niconico_timeshift", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
+
+	return &NicoNicoTimeshiftChannel{
+		BaseChannel: base,
+		config:      cfg,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		apiURL:      nicoMessageServerURL,
+		speed:       speed,
+		clock:       realClock{},
+		log:         logger.WithC("niconico_timeshift", map[string]any{"content_id": cfg.ContentID}),
+	}, nil
+}
+
+func (c *NicoNicoTimeshiftChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
+	c.log.InfoF("Starting NicoNico timeshift channel", map[string]any{"speed": c.speed})
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	// Mark running before run starts, since a failed fetch clears it.
+	c.setRunning(true)
+	go c.run()
+
+	c.log.Info("NicoNico timeshift channel started")
+	return nil
+}
+
+func (c *NicoNicoTimeshiftChannel) Stop(ctx context.Context) error {
+	c.log.Info("Stopping NicoNico timeshift channel")
+	c.setRunning(false)
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *NicoNicoTimeshiftChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.log.DebugF("Dropping outbound message for receive-only channel", map[string]any{
+		"chat_id": msg.ChatID,
+	})
+	return nil
+}
+
+// run fetches the comments and replays them. Playback starts once the fetch
+// has returned, so its latency does not bunch up the first comments. A failed
+// fetch marks the channel as not running, letting the supervisor restart it.
+func (c *NicoNicoTimeshiftChannel) run() {
+	chats, err := c.fetchComments()
+	if err != nil {
+		if c.ctx.Err() == nil {
+			c.log.ErrorF("Failed to fetch comments", map[string]any{"error": err.Error()})
+			c.setRunning(false)
+		}
+		return
+	}
+	c.log.InfoF("Replaying comments", map[string]any{"count": len(chats)})
+
+	start := c.clock.Now()
+	if c.replay(start, chats) {
+		c.log.Info("Replay finished")
+	}
+}
+
+// replay publishes chats, sorted by vpos, each once its scaled vpos has
+// elapsed since start. Waits are measured from start rather than from the
+// previous comment, so time spent publishing does not accumulate. It
+// reports false if the channel was stopped first.
+func (c *NicoNicoTimeshiftChannel) replay(start time.Time, chats []nicoChat) bool {
+	slices.SortStableFunc(chats, func(a, b nicoChat) int {
+		return cmp.Or(cmp.Compare(a.Vpos, b.Vpos), cmp.Compare(a.No, b.No))
+	})
+
+	for _, chat := range chats {
+		due := start.Add(time.Duration(float64(time.Duration(chat.Vpos)*nicoVposUnit) / c.speed))
+		if wait := due.Sub(c.clock.Now()); wait > 0 {
+			select {
+			case <-c.ctx.Done():
+				return false
+			case <-c.clock.After(wait):
+			}
+		}
+		if c.ctx.Err() != nil {
+			return false
+		}
+		c.handleChat(chat)
+	}
+	return true
+}
+
+func (c *NicoNicoTimeshiftChannel) fetchComments() ([]nicoChat, error) {
+	query := url.Values{
+		"thread":   {c.config.ContentID},
+		"version":  {"20061206"},
+		"res_from": {strconv.Itoa(-nicoCommentHistory)},
+	}
+	endpoint := c.apiURL + "/api/thread?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "user_session", Value: c.config.UserSession})
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("niconico returned %d: %s", resp.StatusCode, body)
+	}
+
+	var packet nicoPacket
+	if err := xml.NewDecoder(resp.Body).Decode(&packet); err != nil {
+		return nil, fmt.Errorf("decode comments: %w", err)
+	}
+	return packet.Chats, nil
+}
+
+func (c *NicoNicoTimeshiftChannel) handleChat(chat nicoChat) {
+	if chat.Deleted != 0 || chat.Text == "" {
+		return
+	}
+
+	c.log.DebugF("Replaying comment", map[string]any{
+		"no":      chat.No,
+		"vpos":    chat.Vpos,
+		"preview": utils.Truncate(chat.Text, 50),
+	})
+
+	metadata := map[string]string{
+		"comment_no": strconv.Itoa(chat.No),
+		"vpos":       strconv.FormatInt(chat.Vpos, 10),
+		"mail":       chat.Mail,
+		"content_id": c.config.ContentID,
+	}
+	c.HandleMessage(c.ctx, chat.UserID, c.config.ContentID, chat.Text, nil, metadata)
+}
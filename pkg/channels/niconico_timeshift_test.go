@@ -0,0 +1,229 @@
+package channels
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeClock advances only when waited on, so a replay runs instantly while
+// still seeing the times it would have slept until.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// clockedPublisher records when, on the fake clock, each message arrived.
+type clockedPublisher struct {
+	*bus.MockMessageBus
+	clock *fakeClock
+	mu    sync.Mutex
+	at    []time.Time
+}
+
+func (p *clockedPublisher) PublishInbound(ctx context.Context, msg bus.InboundMessage) error {
+	p.mu.Lock()
+	p.at = append(p.at, p.clock.Now())
+	p.mu.Unlock()
+	return p.MockMessageBus.PublishInbound(ctx, msg)
+}
+
+// timeshiftServer serves the comment fixture and remembers the last request.
+// onRequest, if set, runs before each response.
+type timeshiftServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	query     url.Values
+	session   string
+	onRequest func()
+}
+
+func newTimeshiftServer(t *testing.T) *timeshiftServer {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "niconico", "timeshift.xml"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	s := &timeshiftServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/thread" {
+			http.NotFound(w, r)
+			return
+		}
+		s.mu.Lock()
+		s.query = r.URL.Query()
+		if cookie, err := r.Cookie("user_session"); err == nil {
+			s.session = cookie.Value
+		}
+		onRequest := s.onRequest
+		s.mu.Unlock()
+		if onRequest != nil {
+			onRequest()
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestNewNicoNicoTimeshiftChannelRequiresCredentials(t *testing.T) {
+	if _, err := NewNicoNicoTimeshiftChannel(config.TimeshiftConfig{UserSession: "s"}, bus.NewMockBus()); err == nil {
+		t.Error("expected error without content_id")
+	}
+	if _, err := NewNicoNicoTimeshiftChannel(config.TimeshiftConfig{ContentID: "lv1"}, bus.NewMockBus()); err == nil {
+		t.Error("expected error without user_session")
+	}
+}
+
+func TestNicoNicoTimeshiftReplaysAtScaledTiming(t *testing.T) {
+	srv := newTimeshiftServer(t)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	start := clock.Now()
+	pub := &clockedPublisher{MockMessageBus: bus.NewMockBus(), clock: clock}
+
+	ch, err := NewNicoNicoTimeshiftChannel(config.TimeshiftConfig{
+		ContentID:     "lv123456789",
+		UserSession:   "session-cookie",
+		PlaybackSpeed: 2,
+	}, pub)
+	if err != nil {
+		t.Fatalf("NewNicoNicoTimeshiftChannel: %v", err)
+	}
+	ch.apiURL = srv.URL
+	ch.clock = clock
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+
+	msgs := pub.ExpectInbound(t, 4, 2*time.Second)
+	want := []struct {
+		sender, content string
+		offset          time.Duration
+	}{
+		{"u1", "わこつ", 0},
+		{"u2", "初見です", 750 * time.Millisecond},
+		{"u3", "3コメ", 1500 * time.Millisecond},
+		{"u1", "おつ", 5 * time.Second},
+	}
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	for i, w := range want {
+		got := msgs[i]
+		if got.Channel != "niconico_timeshift" || got.ChatID != "lv123456789" ||
+			got.SenderID != w.sender || got.Content != w.content {
+			t.Errorf("message %d = %+v, want %s %q", i, got, w.sender, w.content)
+		}
+		if offset := pub.at[i].Sub(start); offset != w.offset {
+			t.Errorf("message %d published at +%v, want +%v", i, offset, w.offset)
+		}
+	}
+	if got := msgs[1].Metadata["mail"]; got != "shita red" {
+		t.Errorf("mail = %q", got)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.session != "session-cookie" {
+		t.Errorf("user_session cookie = %q", srv.session)
+	}
+	if got := srv.query.Get("thread"); got != "lv123456789" {
+		t.Errorf("thread = %q", got)
+	}
+}
+
+func TestNicoNicoTimeshiftPlaybackStartsAfterFetch(t *testing.T) {
+	srv := newTimeshiftServer(t)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	// The fetch takes three seconds of playback time.
+	srv.onRequest = func() { clock.After(3 * time.Second) }
+	fetched := clock.Now().Add(3 * time.Second)
+	pub := &clockedPublisher{MockMessageBus: bus.NewMockBus(), clock: clock}
+
+	ch, err := NewNicoNicoTimeshiftChannel(config.TimeshiftConfig{
+		ContentID:   "lv123456789",
+		UserSession: "session-cookie",
+	}, pub)
+	if err != nil {
+		t.Fatalf("NewNicoNicoTimeshiftChannel: %v", err)
+	}
+	ch.apiURL = srv.URL
+	ch.clock = clock
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+
+	pub.ExpectInbound(t, 4, 2*time.Second)
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	for i, want := range []time.Duration{0, 1500 * time.Millisecond, 3 * time.Second, 10 * time.Second} {
+		if offset := pub.at[i].Sub(fetched); offset != want {
+			t.Errorf("message %d published at +%v after the fetch, want +%v", i, offset, want)
+		}
+	}
+}
+
+func TestNicoNicoTimeshiftFetchFailureStopsRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	ch, err := NewNicoNicoTimeshiftChannel(config.TimeshiftConfig{ContentID: "lv1", UserSession: "s"}, bus.NewMockBus())
+	if err != nil {
+		t.Fatalf("NewNicoNicoTimeshiftChannel: %v", err)
+	}
+	ch.apiURL = srv.URL
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ch.IsRunning() {
+		if time.Now().After(deadline) {
+			t.Fatal("channel still reports running after the fetch failed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNicoNicoTimeshiftChannelConformance(t *testing.T) {
+	ChannelConformanceTest(t, func() Channel {
+		ch, err := NewNicoNicoTimeshiftChannel(
+			config.TimeshiftConfig{ContentID: "lv1", UserSession: "s"},
+			bus.NewMockBus(),
+		)
+		if err != nil {
+			t.Fatalf("NewNicoNicoTimeshiftChannel: %v", err)
+		}
+		ch.apiURL = "http://127.0.0.1:0"
+		return ch
+	}, ConformanceOptions{})
+}
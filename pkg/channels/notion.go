@@ -0,0 +1,182 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	notionAPIBase       = "https://api.notion.com/v1"
+	notionVersion       = "2022-06-28"
+	notionMaxAttempts   = 3
+	notionDefaultRetry  = time.Second
+	notionTextLimit     = 2000 // Notion caps each rich_text object at 2000 characters
+	notionTitleLength   = 100
+	notionChatIDProp    = "Chat ID"
+	notionTimestampProp = "Timestamp"
+)
+
+// NotionChannel is an outbound-only channel that appends every message sent
+// to it as a page in a Notion database. The database needs a title property
+// (TitleProperty, "Name" by default), a "Chat ID" text property and a
+// "Timestamp" date property.
+type NotionChannel struct {
+	*BaseChannel
+	config     config.NotionConfig
+	apiBase    string
+	httpClient *http.Client
+}
+
+type notionRichText struct {
+	Type string `json:"type"`
+	Text struct {
+		Content string `json:"content"`
+	} `json:"text"`
+}
+
+func NewNotionChannel(cfg config.NotionConfig, messageBus bus.Publisher) (*NotionChannel, error) {
+	if cfg.IntegrationToken == "" || cfg.DatabaseID == "" {
+		return nil, fmt.Errorf("notion integration_token and database_id are required")
+	}
+	if cfg.TitleProperty == "" {
+		cfg.TitleProperty = "Name"
+	}
+
+	base := NewBaseChannel("notion", cfg, messageBus, nil, nil)
+
+	return &NotionChannel{
+		BaseChannel: base,
+		config:      cfg,
+		apiBase:     notionAPIBase,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Start marks the channel ready. Notion has no inbound side.
+func (c *NotionChannel) Start(ctx context.Context) error {
+	c.setRunning(true)
+	logger.InfoC("notion", "Notion channel started")
+	return nil
+}
+
+func (c *NotionChannel) Stop(ctx context.Context) error {
+	c.setRunning(false)
+	logger.InfoC("notion", "Notion channel stopped")
+	return nil
+}
+
+func (c *NotionChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("notion channel not running")
+	}
+
+	body, err := json.Marshal(c.buildPage(msg, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal page: %w", err)
+	}
+
+	if err := c.createPage(ctx, body); err != nil {
+		return err
+	}
+
+	logger.DebugCF("notion", "Page created", map[string]any{
+		"chat_id": msg.ChatID,
+		"preview": utils.Truncate(msg.Content, 50),
+	})
+	return nil
+}
+
+// buildPage renders msg as a POST /v1/pages request body.
+func (c *NotionChannel) buildPage(msg bus.OutboundMessage, ts time.Time) map[string]any {
+	children := make([]map[string]any, 0, 1)
+	for _, chunk := range utils.SplitMessage(msg.Content, notionTextLimit) {
+		children = append(children, map[string]any{
+			"object": "block",
+			"type":   "paragraph",
+			"paragraph": map[string]any{
+				"rich_text": []notionRichText{newNotionText(chunk)},
+			},
+		})
+	}
+
+	return map[string]any{
+		"parent": map[string]string{"database_id": c.config.DatabaseID},
+		"properties": map[string]any{
+			c.config.TitleProperty: map[string]any{
+				"title": []notionRichText{newNotionText(utils.Truncate(msg.Content, notionTitleLength))},
+			},
+			notionChatIDProp: map[string]any{
+				"rich_text": []notionRichText{newNotionText(msg.ChatID)},
+			},
+			notionTimestampProp: map[string]any{
+				"date": map[string]string{"start": ts.UTC().Format(time.RFC3339)},
+			},
+		},
+		"children": children,
+	}
+}
+
+// createPage posts body, retrying on 429 after the Retry-After delay.
+func (c *NotionChannel) createPage(ctx context.Context, body []byte) error {
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase+"/pages", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.config.IntegrationToken)
+		req.Header.Set("Notion-Version", notionVersion)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("API request failed: %w", err)
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt == notionMaxAttempts {
+			return fmt.Errorf("Notion API error (status %d): %s", resp.StatusCode, string(respBody))
+		}
+
+		wait := retryAfter(resp.Header.Get("Retry-After"), notionDefaultRetry)
+		logger.WarnCF("notion", "Rate limited, retrying", map[string]any{
+			"attempt": attempt,
+			"wait":    wait.String(),
+		})
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func newNotionText(content string) notionRichText {
+	var t notionRichText
+	t.Type = "text"
+	t.Text.Content = content
+	return t
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	secs, err := strconv.Atoi(header)
+	if err != nil || secs < 0 {
+		return fallback
+	}
+	return time.Duration(secs) * time.Second
+}
@@ -0,0 +1,163 @@
+package channels
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestNotionChannel(t *testing.T, handler http.HandlerFunc) *NotionChannel {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	ch, err := NewNotionChannel(config.NotionConfig{
+		IntegrationToken: "secret_abc",
+		DatabaseID:       "db123",
+	}, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewNotionChannel: %v", err)
+	}
+	ch.apiBase = srv.URL
+	if err := ch.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return ch
+}
+
+func TestNewNotionChannelRequiresCredentials(t *testing.T) {
+	if _, err := NewNotionChannel(config.NotionConfig{DatabaseID: "db"}, bus.NewMessageBus()); err == nil {
+		t.Error("expected error for missing integration_token")
+	}
+	if _, err := NewNotionChannel(config.NotionConfig{IntegrationToken: "tok"}, bus.NewMessageBus()); err == nil {
+		t.Error("expected error for missing database_id")
+	}
+}
+
+func TestNotionSendPayload(t *testing.T) {
+	var got map[string]any
+	ch := newTestNotionChannel(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/pages" {
+			t.Errorf("request = %s %s, want POST /pages", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret_abc" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Notion-Version") == "" {
+			t.Error("Notion-Version header missing")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.Write([]byte(`{"object":"page","id":"p1"}`))
+	})
+
+	content := "Summary of today's chat"
+	if err := ch.Send(t.Context(), bus.OutboundMessage{Channel: "notion", ChatID: "telegram:42", Content: content}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if parent := got["parent"].(map[string]any); parent["database_id"] != "db123" {
+		t.Errorf("parent = %v", parent)
+	}
+
+	props := got["properties"].(map[string]any)
+	title := props["Name"].(map[string]any)["title"].([]any)[0].(map[string]any)
+	if title["type"] != "text" || title["text"].(map[string]any)["content"] != content {
+		t.Errorf("title = %v", title)
+	}
+	chatID := props["Chat ID"].(map[string]any)["rich_text"].([]any)[0].(map[string]any)
+	if chatID["text"].(map[string]any)["content"] != "telegram:42" {
+		t.Errorf("chat id = %v", chatID)
+	}
+	start := props["Timestamp"].(map[string]any)["date"].(map[string]any)["start"].(string)
+	if _, err := time.Parse(time.RFC3339, start); err != nil {
+		t.Errorf("timestamp %q is not RFC 3339: %v", start, err)
+	}
+
+	children := got["children"].([]any)
+	if len(children) != 1 {
+		t.Fatalf("children = %d, want 1", len(children))
+	}
+	block := children[0].(map[string]any)
+	if block["object"] != "block" || block["type"] != "paragraph" {
+		t.Errorf("block = %v", block)
+	}
+	text := block["paragraph"].(map[string]any)["rich_text"].([]any)[0].(map[string]any)
+	if text["text"].(map[string]any)["content"] != content {
+		t.Errorf("paragraph text = %v", text)
+	}
+}
+
+func TestNotionSendSplitsLongContent(t *testing.T) {
+	var got struct {
+		Children []json.RawMessage `json:"children"`
+	}
+	ch := newTestNotionChannel(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	})
+
+	content := strings.Repeat("word ", 1000) // 5000 characters
+	if err := ch.Send(t.Context(), bus.OutboundMessage{ChatID: "c", Content: content}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(got.Children) < 3 {
+		t.Fatalf("children = %d, want at least 3 blocks of <= 2000 characters", len(got.Children))
+	}
+}
+
+func TestNotionSendRetriesOnRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	ch := newTestNotionChannel(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	if err := ch.Send(t.Context(), bus.OutboundMessage{ChatID: "c", Content: "hi"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+}
+
+func TestNotionSendGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	ch := newTestNotionChannel(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	if err := ch.Send(t.Context(), bus.OutboundMessage{ChatID: "c", Content: "hi"}); err == nil {
+		t.Fatal("expected error after repeated 429s")
+	}
+	if n := calls.Load(); n != notionMaxAttempts {
+		t.Fatalf("calls = %d, want %d", n, notionMaxAttempts)
+	}
+}
+
+func TestNotionChannelConformance(t *testing.T) {
+	ChannelConformanceTest(t, func() Channel {
+		ch, err := NewNotionChannel(config.NotionConfig{
+			IntegrationToken: "secret_abc",
+			DatabaseID:       "db123",
+		}, bus.NewMockBus())
+		if err != nil {
+			t.Fatalf("NewNotionChannel: %v", err)
+		}
+		ch.apiBase = "http://127.0.0.1:0"
+		return ch
+	}, ConformanceOptions{Message: bus.OutboundMessage{ChatID: "c1", Content: "hello"}})
+}
@@ -97,8 +97,8 @@ type oneBotMessageSegment struct {
 	Data map[string]any `json:"data"`
 }
 
-func NewOneBotChannel(cfg config.OneBotConfig, messageBus *bus.MessageBus) (*OneBotChannel, error) {
-	base := NewBaseChannel("onebot", cfg, messageBus, cfg.AllowFrom)
+func NewOneBotChannel(cfg config.OneBotConfig, messageBus bus.Publisher) (*OneBotChannel, error) {
+	base := NewBaseChannel("onebot", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
 
 	const dedupSize = 1024
 	return &OneBotChannel{
@@ -335,10 +335,11 @@ func (c *OneBotChannel) reconnectLoop() {
 			if conn == nil {
 				logger.InfoC("onebot", "Attempting to reconnect...")
 				if err := c.connect(); err != nil {
-					logger.ErrorCF("onebot", "Reconnect failed", map[string]any{
+					logger.SampledErrorCF("onebot:reconnect", "onebot", "Reconnect failed", map[string]any{
 						"error": err.Error(),
 					})
 				} else {
+					logger.ResetSampled("onebot:reconnect")
 					go c.listen()
 					c.fetchSelfID()
 				}
@@ -930,7 +931,7 @@ func (c *OneBotChannel) handleMessage(raw *oneBotRawEvent) {
 		c.pendingEmojiMsg.Store(chatID, messageID)
 	}
 
-	c.HandleMessage(senderID, chatID, content, parsed.Media, metadata)
+	c.HandleMessage(c.ctx, senderID, chatID, content, parsed.Media, metadata)
 }
 
 func (c *OneBotChannel) isDuplicate(messageID string) bool {
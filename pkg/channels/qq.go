@@ -30,8 +30,8 @@ type QQChannel struct {
 	mu             sync.RWMutex
 }
 
-func NewQQChannel(cfg config.QQConfig, messageBus *bus.MessageBus) (*QQChannel, error) {
-	base := NewBaseChannel("qq", cfg, messageBus, cfg.AllowFrom)
+func NewQQChannel(cfg config.QQConfig, messageBus bus.Publisher) (*QQChannel, error) {
+	base := NewBaseChannel("qq", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
 
 	return &QQChannel{
 		BaseChannel:  base,
@@ -169,7 +169,7 @@ func (c *QQChannel) handleC2CMessage() event.C2CMessageEventHandler {
 			"peer_id":    senderID,
 		}
 
-		c.HandleMessage(senderID, senderID, content, []string{}, metadata)
+		c.HandleMessage(c.ctx, senderID, senderID, content, []string{}, metadata)
 
 		return nil
 	}
@@ -213,7 +213,7 @@ func (c *QQChannel) handleGroupATMessage() event.GroupATMessageEventHandler {
 			"peer_id":    data.GroupID,
 		}
 
-		c.HandleMessage(senderID, data.GroupID, content, []string{}, metadata)
+		c.HandleMessage(c.ctx, senderID, data.GroupID, content, []string{}, metadata)
 
 		return nil
 	}
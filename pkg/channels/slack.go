@@ -2,8 +2,16 @@ package channels
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,11 +27,25 @@ import (
 	"github.com/sipeed/picoclaw/pkg/voice"
 )
 
+const (
+	slackDefaultListenPath = "/webhook/slack"
+	// slackSignatureMaxAge bounds request timestamps to mitigate replay attacks,
+	// matching the window recommended by Slack.
+	slackSignatureMaxAge = 5 * time.Minute
+	// slackMaxEventBody caps Events API request bodies, which are read before
+	// the signature can be checked. Slack's payloads are far smaller.
+	slackMaxEventBody = 1 << 20
+)
+
+// SlackChannel connects to Slack either over Socket Mode (when app_token is
+// set) or by receiving Events API callbacks over HTTP (when signing_secret is
+// set). Outbound messages always use chat.postMessage with the bot token.
 type SlackChannel struct {
 	*BaseChannel
 	config       config.SlackConfig
 	api          *slack.Client
 	socketClient *socketmode.Client
+	httpServer   *http.Server
 	botUserID    string
 	teamID       string
 	transcriber  *voice.GroqTranscriber
@@ -37,26 +59,35 @@ type slackMessageRef struct {
 	Timestamp string
 }
 
-func NewSlackChannel(cfg config.SlackConfig, messageBus *bus.MessageBus) (*SlackChannel, error) {
-	if cfg.BotToken == "" || cfg.AppToken == "" {
-		return nil, fmt.Errorf("slack bot_token and app_token are required")
+func NewSlackChannel(cfg config.SlackConfig, messageBus bus.Publisher) (*SlackChannel, error) {
+	if cfg.BotToken == "" || (cfg.AppToken == "" && cfg.SigningSecret == "") {
+		return nil, fmt.Errorf("slack bot_token and either app_token or signing_secret are required")
 	}
 
-	api := slack.New(
-		cfg.BotToken,
-		slack.OptionAppLevelToken(cfg.AppToken),
-	)
+	base := NewBaseChannel("slack", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
 
-	socketClient := socketmode.New(api)
+	ch := &SlackChannel{
+		BaseChannel: base,
+		config:      cfg,
+	}
+
+	if cfg.AppToken != "" {
+		ch.api = slack.New(
+			cfg.BotToken,
+			slack.OptionAppLevelToken(cfg.AppToken),
+		)
+		ch.socketClient = socketmode.New(ch.api)
+	} else {
+		ch.api = slack.New(cfg.BotToken)
+	}
 
-	base := NewBaseChannel("slack", cfg, messageBus, cfg.AllowFrom)
+	return ch, nil
+}
 
-	return &SlackChannel{
-		BaseChannel:  base,
-		config:       cfg,
-		api:          api,
-		socketClient: socketClient,
-	}, nil
+// usesEventsAPI reports whether the channel receives events over HTTP
+// instead of Socket Mode.
+func (c *SlackChannel) usesEventsAPI() bool {
+	return c.socketClient == nil
 }
 
 func (c *SlackChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
@@ -64,6 +95,10 @@ func (c *SlackChannel) SetTranscriber(transcriber *voice.GroqTranscriber) {
 }
 
 func (c *SlackChannel) Start(ctx context.Context) error {
+	if c.usesEventsAPI() {
+		return c.startEventsAPI(ctx)
+	}
+
 	logger.InfoC("slack", "Starting Slack channel (Socket Mode)")
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
@@ -104,6 +139,16 @@ func (c *SlackChannel) Stop(ctx context.Context) error {
 		c.cancel()
 	}
 
+	if c.httpServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := c.httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.ErrorCF("slack", "Events API server shutdown error", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	c.setRunning(false)
 	logger.InfoC("slack", "Slack channel stopped")
 	return nil
@@ -189,6 +234,139 @@ func (c *SlackChannel) handleEventsAPI(event socketmode.Event) {
 	}
 }
 
+// startEventsAPI launches the HTTP server that receives Events API callbacks.
+func (c *SlackChannel) startEventsAPI(ctx context.Context) error {
+	logger.InfoC("slack", "Starting Slack channel (Events API)")
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+
+	authResp, err := c.api.AuthTestContext(c.ctx)
+	if err != nil {
+		return fmt.Errorf("slack auth test failed: %w", err)
+	}
+	c.botUserID = authResp.UserID
+	c.teamID = authResp.TeamID
+
+	path := c.config.ListenPath
+	if path == "" {
+		path = slackDefaultListenPath
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, c.eventsHandler)
+
+	addr := fmt.Sprintf(":%d", c.config.ListenPort)
+	c.httpServer = &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	go func() {
+		logger.InfoCF("slack", "Slack Events API server listening", map[string]any{
+			"addr": addr,
+			"path": path,
+		})
+		if err := c.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.ErrorCF("slack", "Events API server error", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	c.setRunning(true)
+	logger.InfoC("slack", "Slack channel started (Events API)")
+	return nil
+}
+
+// eventsHandler handles Events API callbacks from Slack.
+func (c *SlackChannel) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxEventBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if !c.verifySignature(body, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature")) {
+		logger.WarnC("slack", "Invalid Events API signature")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		logger.ErrorCF("slack", "Failed to parse Events API payload", map[string]any{
+			"error": err.Error(),
+		})
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	switch event.Type {
+	case slackevents.URLVerification:
+		var challenge slackevents.ChallengeResponse
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(challenge.Challenge))
+	case slackevents.CallbackEvent:
+		// Slack retries if we don't answer within 3 seconds, so acknowledge
+		// first and process the event asynchronously.
+		w.WriteHeader(http.StatusOK)
+		go c.dispatchInnerEvent(event.InnerEvent)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// verifySignature validates X-Slack-Signature (v0=HMAC-SHA256 of
+// "v0:timestamp:body" keyed with the signing secret).
+func (c *SlackChannel) verifySignature(body []byte, timestamp, signature string) bool {
+	if timestamp == "" || signature == "" || c.config.SigningSecret == "" {
+		return false
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.config.SigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (c *SlackChannel) dispatchInnerEvent(inner slackevents.EventsAPIInnerEvent) {
+	switch ev := inner.Data.(type) {
+	case *slackevents.MessageEvent:
+		if c.config.ChannelID != "" && ev.Channel != c.config.ChannelID {
+			return
+		}
+		c.handleMessageEvent(ev)
+	case *slackevents.AppMentionEvent:
+		if c.config.ChannelID != "" && ev.Channel != c.config.ChannelID {
+			return
+		}
+		c.handleAppMention(ev)
+	}
+}
+
 func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 	if ev.User == c.botUserID || ev.User == "" {
 		return
@@ -300,7 +478,7 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 		"has_thread": threadTS != "",
 	})
 
-	c.HandleMessage(senderID, chatID, content, mediaPaths, metadata)
+	c.HandleMessage(c.ctx, senderID, chatID, content, mediaPaths, metadata)
 }
 
 func (c *SlackChannel) handleAppMention(ev *slackevents.AppMentionEvent) {
@@ -361,7 +539,7 @@ func (c *SlackChannel) handleAppMention(ev *slackevents.AppMentionEvent) {
 		"team_id":    c.teamID,
 	}
 
-	c.HandleMessage(senderID, chatID, content, nil, metadata)
+	c.HandleMessage(c.ctx, senderID, chatID, content, nil, metadata)
 }
 
 func (c *SlackChannel) handleSlashCommand(event socketmode.Event) {
@@ -406,7 +584,7 @@ func (c *SlackChannel) handleSlashCommand(event socketmode.Event) {
 		"text":      utils.Truncate(content, 50),
 	})
 
-	c.HandleMessage(senderID, chatID, content, nil, metadata)
+	c.HandleMessage(c.ctx, senderID, chatID, content, nil, metadata)
 }
 
 func (c *SlackChannel) downloadSlackFile(file slack.File) string {
@@ -1,7 +1,18 @@
 package channels
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -123,6 +134,20 @@ func TestNewSlackChannel(t *testing.T) {
 		}
 	})
 
+	t.Run("signing secret without app token", func(t *testing.T) {
+		cfg := config.SlackConfig{
+			BotToken:      "xoxb-test",
+			SigningSecret: "secret",
+		}
+		ch, err := NewSlackChannel(cfg, msgBus)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ch.usesEventsAPI() {
+			t.Error("channel without app_token should use the Events API")
+		}
+	})
+
 	t.Run("valid config", func(t *testing.T) {
 		cfg := config.SlackConfig{
 			BotToken:  "xoxb-test",
@@ -172,3 +197,161 @@ func TestSlackChannelIsAllowed(t *testing.T) {
 		}
 	})
 }
+
+const testSlackSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func signSlackRequest(t *testing.T, req *http.Request, secret, body string) {
+	t.Helper()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func newTestSlackEventsChannel(t *testing.T, channelID string) (*SlackChannel, *bus.BusSpy, *httptest.Server) {
+	t.Helper()
+
+	// Fake Slack Web API so reactions triggered by incoming messages stay local.
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(api.Close)
+
+	msgBus := bus.NewMessageBus()
+	ch, err := NewSlackChannel(config.SlackConfig{
+		BotToken:      "xoxb-test",
+		SigningSecret: testSlackSigningSecret,
+		ChannelID:     channelID,
+	}, msgBus)
+	if err != nil {
+		t.Fatalf("NewSlackChannel: %v", err)
+	}
+	ch.api = slack.New("xoxb-test", slack.OptionAPIURL(api.URL+"/"))
+	ch.botUserID = "UBOT"
+	ch.ctx = t.Context()
+	spy := bus.NewSpy(msgBus)
+
+	srv := httptest.NewServer(http.HandlerFunc(ch.eventsHandler))
+	t.Cleanup(srv.Close)
+	return ch, spy, srv
+}
+
+func postSlackEvent(t *testing.T, url, body, secret string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		signSlackRequest(t, req, secret, body)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func slackMessageEvent(channel, user, text, botID string) string {
+	return `{"type":"event_callback","team_id":"T1","event":{"type":"message","channel":"` + channel +
+		`","user":"` + user + `","text":"` + text + `","ts":"1700000000.000100","bot_id":"` + botID + `"}}`
+}
+
+// expectNoInbound fails if anything is published shortly after the request.
+// The events handler dispatches in the background, so it has to wait.
+func expectNoInbound(t *testing.T, spy *bus.BusSpy) {
+	t.Helper()
+	time.Sleep(200 * time.Millisecond)
+	if msgs := spy.InboundMessages(); len(msgs) != 0 {
+		t.Fatalf("unexpected inbound message: %+v", msgs[0])
+	}
+}
+
+func TestSlackEventsAPIValidSignaturePublishes(t *testing.T) {
+	_, spy, srv := newTestSlackEventsChannel(t, "")
+
+	resp := postSlackEvent(t, srv.URL, slackMessageEvent("C123", "U42", "hello bot", ""), testSlackSigningSecret)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	msg := spy.AssertInbound(t, 1, 2*time.Second)[0]
+	if msg.Channel != "slack" || msg.SenderID != "U42" || msg.ChatID != "C123" || msg.Content != "hello bot" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestSlackEventsAPIRejectsInvalidSignature(t *testing.T) {
+	_, spy, srv := newTestSlackEventsChannel(t, "")
+	body := slackMessageEvent("C123", "U42", "hello", "")
+
+	for name, secret := range map[string]string{"unsigned": "", "wrong secret": "not-the-secret"} {
+		t.Run(name, func(t *testing.T) {
+			resp := postSlackEvent(t, srv.URL, body, secret)
+			if resp.StatusCode != http.StatusForbidden {
+				t.Fatalf("status = %d, want 403", resp.StatusCode)
+			}
+		})
+	}
+	expectNoInbound(t, spy)
+}
+
+func TestSlackEventsAPIRejectsOversizedBody(t *testing.T) {
+	_, spy, srv := newTestSlackEventsChannel(t, "")
+	body := slackMessageEvent("C123", "U42", strings.Repeat("a", slackMaxEventBody), "")
+
+	resp := postSlackEvent(t, srv.URL, body, testSlackSigningSecret)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", resp.StatusCode)
+	}
+	expectNoInbound(t, spy)
+}
+
+func TestSlackEventsAPIRejectsStaleTimestamp(t *testing.T) {
+	ch := &SlackChannel{config: config.SlackConfig{SigningSecret: testSlackSigningSecret}}
+	body := []byte("{}")
+	ts := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSlackSigningSecret))
+	mac.Write([]byte("v0:" + ts + ":" + string(body)))
+	sig := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if ch.verifySignature(body, ts, sig) {
+		t.Fatal("stale timestamp should be rejected")
+	}
+}
+
+func TestSlackEventsAPIURLVerification(t *testing.T) {
+	_, _, srv := newTestSlackEventsChannel(t, "")
+	body := `{"type":"url_verification","token":"x","challenge":"abc123"}`
+
+	resp := postSlackEvent(t, srv.URL, body, testSlackSigningSecret)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abc123" {
+		t.Fatalf("challenge response = %q, want %q", got, "abc123")
+	}
+}
+
+func TestSlackEventsAPIIgnoresBotMessages(t *testing.T) {
+	_, spy, srv := newTestSlackEventsChannel(t, "")
+
+	postSlackEvent(t, srv.URL, slackMessageEvent("C123", "U42", "echo", "B99"), testSlackSigningSecret)
+	postSlackEvent(t, srv.URL, slackMessageEvent("C123", "UBOT", "self", ""), testSlackSigningSecret)
+	expectNoInbound(t, spy)
+}
+
+func TestSlackEventsAPIChannelFilter(t *testing.T) {
+	_, spy, srv := newTestSlackEventsChannel(t, "CALLOWED")
+
+	postSlackEvent(t, srv.URL, slackMessageEvent("COTHER", "U42", "wrong room", ""), testSlackSigningSecret)
+	expectNoInbound(t, spy)
+}
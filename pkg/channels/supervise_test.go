@@ -0,0 +1,95 @@
+package channels
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channelmanager"
+)
+
+// crashingChannel drops its session from its own goroutine shortly after each
+// start, the way QQ and OneBot do on a lost connection, until it has crashed
+// `crashes` times.
+type crashingChannel struct {
+	*BaseChannel
+	crashes int32
+	starts  atomic.Int32
+	stops   atomic.Int32
+	// sessions counts session goroutines that have not yet exited.
+	sessions atomic.Int32
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+}
+
+func (c *crashingChannel) Start(ctx context.Context) error {
+	n := c.starts.Add(1)
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+
+	c.setRunning(true)
+	c.sessions.Add(1)
+	go func() {
+		defer c.sessions.Add(-1)
+		if n <= c.crashes {
+			time.Sleep(2 * time.Millisecond)
+			c.setRunning(false)
+		}
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+func (c *crashingChannel) Stop(ctx context.Context) error {
+	c.stops.Add(1)
+	c.setRunning(false)
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *crashingChannel) Send(ctx context.Context, msg bus.OutboundMessage) error { return nil }
+
+func TestSupervisorStopsCrashedChannelBeforeRestart(t *testing.T) {
+	ch := &crashingChannel{
+		BaseChannel: NewBaseChannel("crashing", nil, bus.NewMockBus(), nil, nil),
+		crashes:     3,
+	}
+	m := channelmanager.NewChannelManager(channelmanager.Config{
+		RestartDelay:  time.Millisecond,
+		MaxRestarts:   5,
+		RestartWindow: time.Minute,
+		PollInterval:  time.Millisecond,
+	})
+	m.Register("crashing", ch)
+	m.Start(t.Context())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ch.starts.Load() < 4 || !ch.IsRunning() {
+		if time.Now().After(deadline) {
+			t.Fatalf("channel did not recover: %d starts", ch.starts.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	defer m.Stop()
+
+	if got := ch.stops.Load(); got != 3 {
+		t.Errorf("Stop called %d times, want 3 (once per restart)", got)
+	}
+	// Only the live instance's session may remain; earlier ones were stopped.
+	deadline = time.Now().Add(time.Second)
+	for ch.sessions.Load() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d session goroutines running, want 1", ch.sessions.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,243 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	twicasAPIURL          = "https://apiv2.twitcasting.tv"
+	twicasDefaultPoll     = 5 * time.Second
+	twicasCommentPageSize = 50
+)
+
+// TwicasChannel reads live comments from a TwitCasting movie by polling the
+// comments API. Each poll asks only for comments newer than the last one
+// seen, using the slice_id cursor. It does not post comments, so outbound
+// messages addressed to it are dropped.
+type TwicasChannel struct {
+	*BaseChannel
+	config     config.TwicasConfig
+	httpClient *http.Client
+	apiURL     string
+	interval   time.Duration
+	ngWords    ngWordFilter
+	log        *logger.Logger
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	// cursor is the ID of the newest comment seen; empty until the first
+	// poll. Only the poll goroutine touches it.
+	cursor string
+}
+
+type twicasCommentsResponse struct {
+	MovieID  string          `json:"movie_id"`
+	AllCount int             `json:"all_count"`
+	Comments []twicasComment `json:"comments"`
+}
+
+type twicasComment struct {
+	ID       string `json:"id"`
+	Message  string `json:"message"`
+	FromUser struct {
+		ID       string `json:"id"`
+		ScreenID string `json:"screen_id"`
+		Name     string `json:"name"`
+	} `json:"from_user"`
+	Created int64 `json:"created"`
+}
+
+func NewTwicasChannel(cfg config.TwicasConfig, messageBus bus.Publisher) (*TwicasChannel, error) {
+	if cfg.MovieID == "" || cfg.AccessToken == "" {
+		return nil, fmt.Errorf("twicas movie_id and access_token are required")
+	}
+
+	interval := twicasDefaultPoll
+	if cfg.PollIntervalSeconds > 0 {
+		interval = time.Duration(cfg.PollIntervalSeconds) * time.Second
+	}
+
+	base := NewBaseChannel("twicas", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
+
+	return &TwicasChannel{
+		BaseChannel: base,
+		config:      cfg,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		apiURL:      twicasAPIURL,
+		interval:    interval,
+		ngWords:     newNGWordFilter(cfg.NGWords),
+		log:         logger.WithC("twicas", map[string]any{"movie_id": cfg.MovieID}),
+	}, nil
+}
+
+func (c *TwicasChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
+	c.log.InfoF("Starting TwitCasting channel", map[string]any{"interval": c.interval.String()})
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	go c.run()
+
+	c.setRunning(true)
+	c.log.Info("TwitCasting channel started")
+	return nil
+}
+
+func (c *TwicasChannel) Stop(ctx context.Context) error {
+	c.log.Info("Stopping TwitCasting channel")
+	c.setRunning(false)
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return nil
+}
+
+func (c *TwicasChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.log.DebugF("Dropping outbound message for receive-only channel", map[string]any{
+		"chat_id": msg.ChatID,
+	})
+	return nil
+}
+
+func (c *TwicasChannel) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	// An outage fails every poll, so the warning is sampled.
+	errKey := "twicas:" + c.config.MovieID + ":poll"
+	for {
+		if err := c.poll(); err == nil {
+			logger.ResetSampled(errKey)
+		} else if c.ctx.Err() == nil {
+			c.log.SampledWarnF(errKey, "Failed to fetch comments", map[string]any{"error": err.Error()})
+		}
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the comments posted since the cursor and publishes them
+// oldest first. The first poll only records where the stream currently is,
+// so joining a running broadcast does not replay its history.
+func (c *TwicasChannel) poll() error {
+	comments, err := c.fetchComments(c.cursor)
+	if err != nil {
+		return err
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+
+	// The API returns the newest comment first.
+	slices.SortFunc(comments, func(a, b twicasComment) int {
+		return compareCommentIDs(a.ID, b.ID)
+	})
+
+	priming := c.cursor == ""
+	for _, comment := range comments {
+		if compareCommentIDs(comment.ID, c.cursor) <= 0 {
+			continue
+		}
+		c.cursor = comment.ID
+		if !priming {
+			c.handleComment(comment)
+		}
+	}
+	return nil
+}
+
+func (c *TwicasChannel) fetchComments(sliceID string) ([]twicasComment, error) {
+	query := url.Values{"limit": {strconv.Itoa(twicasCommentPageSize)}}
+	if sliceID != "" {
+		query.Set("slice_id", sliceID)
+	}
+	endpoint := fmt.Sprintf("%s/movies/%s/comments?%s", c.apiURL, url.PathEscape(c.config.MovieID), query.Encode())
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Api-Version", "2.0")
+	req.Header.Set("Authorization", "Bearer "+c.config.AccessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("twitcasting returned %d: %s", resp.StatusCode, body)
+	}
+
+	var result twicasCommentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode comments: %w", err)
+	}
+	return result.Comments, nil
+}
+
+func (c *TwicasChannel) handleComment(comment twicasComment) {
+	if word, ok := c.ngWords.match(comment.Message); ok {
+		c.log.DebugF("Dropping comment containing NG word", map[string]any{
+			"comment_id": comment.ID,
+			"word":       word,
+		})
+		return
+	}
+
+	content := c.formatComment(comment)
+	c.log.InfoF("Received comment", map[string]any{
+		"user_id": comment.FromUser.ID,
+		"preview": utils.Truncate(content, 50),
+	})
+
+	metadata := map[string]string{
+		"comment_id": comment.ID,
+		"movie_id":   c.config.MovieID,
+		"screen_id":  comment.FromUser.ScreenID,
+		"user_name":  comment.FromUser.Name,
+	}
+	c.HandleMessage(c.ctx, comment.FromUser.ID, c.config.MovieID, content, nil, metadata)
+}
+
+// formatComment fills the configured message format. Supported placeholders
+// are {message}, {user}, {screen_id} and {user_id}.
+func (c *TwicasChannel) formatComment(comment twicasComment) string {
+	return expandMessageFormat(c.config.MessageFormat, map[string]string{
+		"message":   comment.Message,
+		"user":      comment.FromUser.Name,
+		"screen_id": comment.FromUser.ScreenID,
+		"user_id":   comment.FromUser.ID,
+	})
+}
+
+// compareCommentIDs orders TwitCasting comment IDs, which are decimal
+// strings that grow over time. An empty ID sorts first.
+func compareCommentIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
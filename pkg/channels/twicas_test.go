@@ -0,0 +1,186 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeTwicasAPI serves the comments endpoint for one movie, honouring
+// slice_id the way TwitCasting does: only newer comments, newest first.
+type fakeTwicasAPI struct {
+	mu       sync.Mutex
+	comments []twicasComment // oldest first
+	sliceIDs []string
+	auth     []string
+}
+
+func (f *fakeTwicasAPI) add(id, user, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := twicasComment{ID: id, Message: message}
+	c.FromUser.ID = "u-" + user
+	c.FromUser.ScreenID = user
+	c.FromUser.Name = strings.ToUpper(user)
+	f.comments = append(f.comments, c)
+}
+
+func (f *fakeTwicasAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != "/movies/m1/comments" {
+		http.NotFound(w, r)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sliceID := r.URL.Query().Get("slice_id")
+	f.sliceIDs = append(f.sliceIDs, sliceID)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	resp := twicasCommentsResponse{MovieID: "m1", AllCount: len(f.comments), Comments: []twicasComment{}}
+	for i := len(f.comments) - 1; i >= 0; i-- {
+		if compareCommentIDs(f.comments[i].ID, sliceID) > 0 {
+			resp.Comments = append(resp.Comments, f.comments[i])
+		}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newTestTwicasChannel(
+	t *testing.T,
+	api *fakeTwicasAPI,
+	cfg config.TwicasConfig,
+) (*TwicasChannel, *bus.MockMessageBus) {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	msgBus := bus.NewMockBus()
+	cfg.MovieID = "m1"
+	cfg.AccessToken = "secret-token"
+	ch, err := NewTwicasChannel(cfg, msgBus)
+	if err != nil {
+		t.Fatalf("NewTwicasChannel: %v", err)
+	}
+	ch.apiURL = srv.URL
+	ch.ctx, ch.cancel = context.WithCancel(context.Background())
+	t.Cleanup(ch.cancel)
+	return ch, msgBus
+}
+
+func TestTwicasPollAdvancesCursor(t *testing.T) {
+	api := &fakeTwicasAPI{}
+	api.add("101", "alice", "before we joined")
+	api.add("102", "bob", "also old")
+
+	ch, msgBus := newTestTwicasChannel(t, api, config.TwicasConfig{
+		MessageFormat: "{user} (@{screen_id}): {message}",
+		NGWords:       config.FlexibleStringSlice{"SPAM"},
+	})
+
+	if err := ch.poll(); err != nil {
+		t.Fatalf("first poll: %v", err)
+	}
+	if ch.cursor != "102" {
+		t.Fatalf("cursor after first poll = %q, want 102", ch.cursor)
+	}
+	if msgs := msgBus.InboundMessages(); len(msgs) != 0 {
+		t.Fatalf("first poll published backlog comments %+v", msgs)
+	}
+
+	api.add("103", "carol", "hello")
+	api.add("104", "dave", "buy spam here")
+	api.add("105", "erin", "hi")
+
+	if err := ch.poll(); err != nil {
+		t.Fatalf("second poll: %v", err)
+	}
+	if ch.cursor != "105" {
+		t.Errorf("cursor after second poll = %q, want 105", ch.cursor)
+	}
+
+	want := []struct{ sender, content, commentID string }{
+		{"u-carol", "CAROL (@carol): hello", "103"},
+		{"u-erin", "ERIN (@erin): hi", "105"},
+	}
+	msgs := msgBus.InboundMessages()
+	if len(msgs) != len(want) {
+		t.Fatalf("published %d messages, want %d (NG word comment dropped): %+v", len(msgs), len(want), msgs)
+	}
+	for i, w := range want {
+		msg := msgs[i]
+		if msg.Channel != "twicas" || msg.ChatID != "m1" || msg.SenderID != w.sender {
+			t.Errorf("message routing = %s/%s/%s", msg.Channel, msg.ChatID, msg.SenderID)
+		}
+		if msg.Content != w.content {
+			t.Errorf("content = %q, want %q", msg.Content, w.content)
+		}
+		if msg.Metadata["comment_id"] != w.commentID {
+			t.Errorf("comment_id = %q, want %q", msg.Metadata["comment_id"], w.commentID)
+		}
+	}
+
+	if err := ch.poll(); err != nil {
+		t.Fatalf("third poll: %v", err)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	wantSlices := []string{"", "102", "105"}
+	if strings.Join(api.sliceIDs, ",") != strings.Join(wantSlices, ",") {
+		t.Errorf("slice_id sequence = %q, want %q", api.sliceIDs, wantSlices)
+	}
+	for _, auth := range api.auth {
+		if auth != "Bearer secret-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+	}
+}
+
+func TestTwicasPollReportsAPIError(t *testing.T) {
+	ch, _ := newTestTwicasChannel(t, &fakeTwicasAPI{}, config.TwicasConfig{})
+	ch.config.MovieID = "missing"
+
+	if err := ch.poll(); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("poll() error = %v, want 404", err)
+	}
+	if ch.cursor != "" {
+		t.Errorf("cursor moved on error: %q", ch.cursor)
+	}
+}
+
+func TestCompareCommentIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"9", "10", -1},
+		{"10", "9", 1},
+		{"123", "123", 0},
+		{"", "1", -1},
+	}
+	for _, tt := range tests {
+		got := compareCommentIDs(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("compareCommentIDs(%q, %q) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTwicasChannelConformance(t *testing.T) {
+	ChannelConformanceTest(t, func() Channel {
+		ch, err := NewTwicasChannel(config.TwicasConfig{MovieID: "m1", AccessToken: "tok"}, bus.NewMockBus())
+		if err != nil {
+			t.Fatalf("NewTwicasChannel: %v", err)
+		}
+		ch.apiURL = "http://127.0.0.1:0"
+		return ch
+	}, ConformanceOptions{})
+}
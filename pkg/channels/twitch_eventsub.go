@@ -0,0 +1,373 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	twitchEventSubURL  = "wss://eventsub.wss.twitch.tv/ws"
+	twitchHelixURL     = "https://api.twitch.tv/helix"
+	twitchRetryDelay   = 5 * time.Second
+	twitchWelcomeGrace = 15 * time.Second
+)
+
+// TwitchEventSubChannel receives follow and subscription events for one
+// broadcaster over the EventSub WebSocket transport. It does not carry chat,
+// so outbound messages addressed to it are dropped.
+type TwitchEventSubChannel struct {
+	*BaseChannel
+	config     config.TwitchEventSubConfig
+	httpClient *http.Client
+	wsURL      string
+	helixURL   string
+	conn       *websocket.Conn
+	mu         sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// twitchEventSubMessage is the envelope shared by every EventSub WebSocket message.
+type twitchEventSubMessage struct {
+	Metadata struct {
+		MessageID        string `json:"message_id"`
+		MessageType      string `json:"message_type"`
+		SubscriptionType string `json:"subscription_type"`
+	} `json:"metadata"`
+	Payload struct {
+		Session *struct {
+			ID                      string `json:"id"`
+			KeepaliveTimeoutSeconds int    `json:"keepalive_timeout_seconds"`
+			ReconnectURL            string `json:"reconnect_url"`
+		} `json:"session"`
+		Subscription *struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"subscription"`
+		Event json.RawMessage `json:"event"`
+	} `json:"payload"`
+}
+
+// twitchEventSubEvent holds the fields used from channel.follow and channel.subscribe events.
+type twitchEventSubEvent struct {
+	UserID              string `json:"user_id"`
+	UserLogin           string `json:"user_login"`
+	UserName            string `json:"user_name"`
+	BroadcasterUserID   string `json:"broadcaster_user_id"`
+	BroadcasterUserName string `json:"broadcaster_user_name"`
+	Tier                string `json:"tier"`
+	IsGift              bool   `json:"is_gift"`
+}
+
+type twitchSubscriptionRequest struct {
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition map[string]string `json:"condition"`
+	Transport struct {
+		Method    string `json:"method"`
+		SessionID string `json:"session_id"`
+	} `json:"transport"`
+}
+
+func NewTwitchEventSubChannel(
+	cfg config.TwitchEventSubConfig,
+	messageBus bus.Publisher,
+) (*TwitchEventSubChannel, error) {
+	if cfg.ClientID == "" || cfg.UserAccessToken == "" {
+		return nil, fmt.Errorf("twitch eventsub client_id and user_access_token are required")
+	}
+	if cfg.BroadcasterUserID == "" {
+		return nil, fmt.Errorf("twitch eventsub broadcaster_user_id is required")
+	}
+
+	base := NewBaseChannel("twitch_eventsub", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
+
+	return &TwitchEventSubChannel{
+		BaseChannel: base,
+		config:      cfg,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		wsURL:       twitchEventSubURL,
+		helixURL:    twitchHelixURL,
+	}, nil
+}
+
+func (c *TwitchEventSubChannel) Start(ctx context.Context) error {
+	if c.IsRunning() {
+		return nil
+	}
+	logger.InfoC("twitch_eventsub", "Starting Twitch EventSub channel")
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	go c.run()
+
+	c.setRunning(true)
+	logger.InfoC("twitch_eventsub", "Twitch EventSub channel started")
+	return nil
+}
+
+func (c *TwitchEventSubChannel) Stop(ctx context.Context) error {
+	logger.InfoC("twitch_eventsub", "Stopping Twitch EventSub channel")
+	c.setRunning(false)
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.mu.Lock()
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *TwitchEventSubChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	logger.DebugCF("twitch_eventsub", "Dropping outbound message for receive-only channel", map[string]any{
+		"chat_id": msg.ChatID,
+	})
+	return nil
+}
+
+// run keeps a session open until the channel is stopped. A session_reconnect
+// moves to the URL Twitch supplies, where existing subscriptions carry over;
+// any other disconnect starts a fresh session and subscribes again.
+func (c *TwitchEventSubChannel) run() {
+	url, subscribe := c.wsURL, true
+	for {
+		reconnectURL, err := c.session(url, subscribe)
+		if c.ctx.Err() != nil {
+			return
+		}
+		if reconnectURL != "" {
+			logger.InfoC("twitch_eventsub", "Server requested reconnect")
+			url, subscribe = reconnectURL, false
+			continue
+		}
+
+		logger.SampledWarnCF(c.retryLogKey(), "twitch_eventsub", "Session ended, retrying", map[string]any{
+			"error": fmt.Sprint(err),
+			"delay": twitchRetryDelay.String(),
+		})
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(twitchRetryDelay):
+		}
+		url, subscribe = c.wsURL, true
+	}
+}
+
+// session reads one WebSocket connection until it fails or Twitch asks the
+// client to move, in which case the new URL is returned. Twitch closes the
+// connection if the client writes anything, so keepalives are acknowledged
+// by extending the read deadline; WebSocket pings are answered by the
+// default pong handler.
+func (c *TwitchEventSubChannel) session(url string, subscribe bool) (string, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+	conn, resp, err := dialer.DialContext(c.ctx, url, nil)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("dial %s: %w", url, err)
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.mu.Unlock()
+		conn.Close()
+	}()
+
+	keepalive := twitchWelcomeGrace
+	_ = conn.SetReadDeadline(time.Now().Add(keepalive))
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return "", err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(keepalive))
+
+		var msg twitchEventSubMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.WarnCF("twitch_eventsub", "Failed to parse message", map[string]any{
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		switch msg.Metadata.MessageType {
+		case "session_welcome":
+			if msg.Payload.Session == nil {
+				return "", fmt.Errorf("session_welcome without session")
+			}
+			if secs := msg.Payload.Session.KeepaliveTimeoutSeconds; secs > 0 {
+				keepalive = time.Duration(secs)*time.Second + twitchRetryDelay
+				_ = conn.SetReadDeadline(time.Now().Add(keepalive))
+			}
+			logger.InfoCF("twitch_eventsub", "Session established", map[string]any{
+				"session_id": msg.Payload.Session.ID,
+			})
+			logger.ResetSampled(c.retryLogKey())
+			if subscribe {
+				if err := c.subscribeAll(msg.Payload.Session.ID); err != nil {
+					return "", err
+				}
+			}
+		case "session_keepalive":
+			// Deadline already extended above.
+		case "session_reconnect":
+			if msg.Payload.Session == nil || msg.Payload.Session.ReconnectURL == "" {
+				return "", fmt.Errorf("session_reconnect without reconnect_url")
+			}
+			return msg.Payload.Session.ReconnectURL, nil
+		case "notification":
+			c.handleNotification(msg.Metadata.SubscriptionType, msg.Metadata.MessageID, msg.Payload.Event)
+		case "revocation":
+			if sub := msg.Payload.Subscription; sub != nil {
+				logger.WarnCF("twitch_eventsub", "Subscription revoked", map[string]any{
+					"type":   sub.Type,
+					"status": sub.Status,
+				})
+			}
+		default:
+			logger.DebugCF("twitch_eventsub", "Ignoring message", map[string]any{
+				"message_type": msg.Metadata.MessageType,
+			})
+		}
+	}
+}
+
+// retryLogKey samples the retry warning, which repeats while Twitch is
+// unreachable.
+func (c *TwitchEventSubChannel) retryLogKey() string {
+	return "twitch_eventsub:" + c.config.BroadcasterUserID + ":session"
+}
+
+// subscribeAll creates the channel.follow and channel.subscribe subscriptions
+// for sessionID. Twitch requires this within ten seconds of the welcome.
+func (c *TwitchEventSubChannel) subscribeAll(sessionID string) error {
+	broadcaster := c.config.BroadcasterUserID
+	subs := []twitchSubscriptionRequest{
+		{
+			Type:    "channel.follow",
+			Version: "2",
+			Condition: map[string]string{
+				"broadcaster_user_id": broadcaster,
+				"moderator_user_id":   broadcaster,
+			},
+		},
+		{
+			Type:      "channel.subscribe",
+			Version:   "1",
+			Condition: map[string]string{"broadcaster_user_id": broadcaster},
+		},
+	}
+
+	for _, sub := range subs {
+		sub.Transport.Method = "websocket"
+		sub.Transport.SessionID = sessionID
+		if err := c.createSubscription(sub); err != nil {
+			return fmt.Errorf("subscribe %s: %w", sub.Type, err)
+		}
+	}
+	return nil
+}
+
+func (c *TwitchEventSubChannel) createSubscription(sub twitchSubscriptionRequest) error {
+	body, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		c.ctx,
+		http.MethodPost,
+		c.helixURL+"/eventsub/subscriptions",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Client-Id", c.config.ClientID)
+	req.Header.Set("Authorization", "Bearer "+c.config.UserAccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("helix returned %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+func (c *TwitchEventSubChannel) handleNotification(subType, messageID string, raw json.RawMessage) {
+	var event twitchEventSubEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		logger.WarnCF("twitch_eventsub", "Failed to parse event", map[string]any{
+			"type":  subType,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	metadata := map[string]string{
+		"message_id": messageID,
+		"user_login": event.UserLogin,
+		"user_name":  event.UserName,
+	}
+
+	var content string
+	switch subType {
+	case "channel.follow":
+		metadata["event"] = "follow"
+		content = fmt.Sprintf("%s followed the channel", event.UserName)
+	case "channel.subscribe":
+		metadata["event"] = "subscription"
+		metadata["tier"] = event.Tier
+		metadata["is_gift"] = fmt.Sprint(event.IsGift)
+		content = fmt.Sprintf("%s subscribed at tier %s", event.UserName, event.Tier)
+		if event.IsGift {
+			content += " (gift)"
+		}
+	default:
+		logger.DebugCF("twitch_eventsub", "Ignoring notification", map[string]any{
+			"type": subType,
+		})
+		return
+	}
+
+	logger.InfoCF("twitch_eventsub", "Received event", map[string]any{
+		"event":   metadata["event"],
+		"user_id": event.UserID,
+		"preview": utils.Truncate(content, 50),
+	})
+
+	c.HandleMessage(c.ctx, event.UserID, event.BroadcasterUserID, content, nil, metadata)
+}
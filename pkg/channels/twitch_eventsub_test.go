@@ -0,0 +1,222 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func loadEventSubFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "twitch_eventsub", name+".json"))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// newEventSubReplayServer serves a WebSocket that writes frames in order and
+// then holds the connection open until the test ends.
+func newEventSubReplayServer(t *testing.T, frames ...[]byte) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, frame := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		}
+		<-done
+	}))
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+	return srv
+}
+
+type helixRecorder struct {
+	mu   sync.Mutex
+	subs []twitchSubscriptionRequest
+	hdrs []http.Header
+}
+
+func (h *helixRecorder) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+func newHelixServer(t *testing.T) (*httptest.Server, *helixRecorder) {
+	t.Helper()
+	rec := &helixRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/eventsub/subscriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		var sub twitchSubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rec.mu.Lock()
+		rec.subs = append(rec.subs, sub)
+		rec.hdrs = append(rec.hdrs, r.Header.Clone())
+		rec.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"data":[],"total":1}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func startTestEventSubChannel(t *testing.T, ws, helix string) *bus.BusSpy {
+	t.Helper()
+	msgBus := bus.NewMessageBus()
+	ch, err := NewTwitchEventSubChannel(config.TwitchEventSubConfig{
+		ClientID:          "client-id",
+		UserAccessToken:   "token",
+		BroadcasterUserID: "1337",
+	}, msgBus)
+	if err != nil {
+		t.Fatalf("NewTwitchEventSubChannel: %v", err)
+	}
+	ch.wsURL = ws
+	ch.helixURL = helix
+	spy := bus.NewSpy(msgBus)
+
+	if err := ch.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+	return spy
+}
+
+func TestNewTwitchEventSubChannelRequiresCredentials(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	for name, cfg := range map[string]config.TwitchEventSubConfig{
+		"missing token":       {ClientID: "id", BroadcasterUserID: "1"},
+		"missing client id":   {UserAccessToken: "tok", BroadcasterUserID: "1"},
+		"missing broadcaster": {ClientID: "id", UserAccessToken: "tok"},
+	} {
+		if _, err := NewTwitchEventSubChannel(cfg, msgBus); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestTwitchEventSubHandshakeAndEvents(t *testing.T) {
+	helix, rec := newHelixServer(t)
+	ws := newEventSubReplayServer(t,
+		loadEventSubFixture(t, "session_welcome"),
+		loadEventSubFixture(t, "session_keepalive"),
+		loadEventSubFixture(t, "notification_follow"),
+		loadEventSubFixture(t, "notification_subscribe"),
+	)
+	spy := startTestEventSubChannel(t, wsURL(ws), helix.URL)
+
+	msgs := spy.AssertInbound(t, 2, 2*time.Second)
+	follow := msgs[0]
+	if follow.Channel != "twitch_eventsub" || follow.SenderID != "1234" || follow.ChatID != "1337" {
+		t.Fatalf("unexpected follow message: %+v", follow)
+	}
+	if follow.Metadata["event"] != "follow" || follow.Metadata["user_login"] != "cool_user" {
+		t.Fatalf("follow metadata = %v", follow.Metadata)
+	}
+	if follow.Content != "Cool_User followed the channel" {
+		t.Fatalf("follow content = %q", follow.Content)
+	}
+
+	sub := msgs[1]
+	if sub.SenderID != "5678" || sub.Metadata["event"] != "subscription" {
+		t.Fatalf("unexpected subscription message: %+v", sub)
+	}
+	if sub.Metadata["tier"] != "1000" || sub.Metadata["is_gift"] != "true" {
+		t.Fatalf("subscription metadata = %v", sub.Metadata)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.subs) != 2 {
+		t.Fatalf("created %d subscriptions, want 2", len(rec.subs))
+	}
+	types := map[string]twitchSubscriptionRequest{}
+	for i, s := range rec.subs {
+		types[s.Type] = s
+		if s.Transport.Method != "websocket" || s.Transport.SessionID != "AQoQILE98gtqShGmLD7AM6yJThAB" {
+			t.Errorf("subscription %s transport = %+v", s.Type, s.Transport)
+		}
+		if rec.hdrs[i].Get("Client-Id") != "client-id" || rec.hdrs[i].Get("Authorization") != "Bearer token" {
+			t.Errorf("subscription %s missing auth headers", s.Type)
+		}
+	}
+	if f := types["channel.follow"]; f.Version != "2" || f.Condition["moderator_user_id"] != "1337" {
+		t.Errorf("channel.follow request = %+v", f)
+	}
+	if s := types["channel.subscribe"]; s.Version != "1" || s.Condition["broadcaster_user_id"] != "1337" {
+		t.Errorf("channel.subscribe request = %+v", s)
+	}
+}
+
+func TestTwitchEventSubReconnectKeepsSubscriptions(t *testing.T) {
+	helix, rec := newHelixServer(t)
+	second := newEventSubReplayServer(t,
+		loadEventSubFixture(t, "session_welcome"),
+		loadEventSubFixture(t, "notification_follow"),
+	)
+	reconnect := strings.ReplaceAll(
+		string(loadEventSubFixture(t, "session_reconnect")),
+		"{{reconnect_url}}",
+		wsURL(second),
+	)
+	first := newEventSubReplayServer(t,
+		loadEventSubFixture(t, "session_welcome"),
+		[]byte(reconnect),
+	)
+	spy := startTestEventSubChannel(t, wsURL(first), helix.URL)
+
+	if msg := spy.AssertInbound(t, 1, 2*time.Second)[0]; msg.Metadata["event"] != "follow" {
+		t.Fatalf("unexpected message after reconnect: %+v", msg)
+	}
+	if n := rec.count(); n != 2 {
+		t.Fatalf("created %d subscriptions, want 2 (reconnect must not resubscribe)", n)
+	}
+}
+
+func TestTwitchEventSubChannelConformance(t *testing.T) {
+	ChannelConformanceTest(t, func() Channel {
+		ch, err := NewTwitchEventSubChannel(config.TwitchEventSubConfig{
+			ClientID:          "client-id",
+			UserAccessToken:   "token",
+			BroadcasterUserID: "1337",
+		}, bus.NewMockBus())
+		if err != nil {
+			t.Fatalf("NewTwitchEventSubChannel: %v", err)
+		}
+		ch.wsURL = "ws://127.0.0.1:0"
+		return ch
+	}, ConformanceOptions{})
+}
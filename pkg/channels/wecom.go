@@ -91,12 +91,12 @@ type WeComBotReplyMessage struct {
 }
 
 // NewWeComBotChannel creates a new WeCom Bot channel instance
-func NewWeComBotChannel(cfg config.WeComConfig, messageBus *bus.MessageBus) (*WeComBotChannel, error) {
+func NewWeComBotChannel(cfg config.WeComConfig, messageBus bus.Publisher) (*WeComBotChannel, error) {
 	if cfg.Token == "" || cfg.WebhookURL == "" {
 		return nil, fmt.Errorf("wecom token and webhook_url are required")
 	}
 
-	base := NewBaseChannel("wecom", cfg, messageBus, cfg.AllowFrom)
+	base := NewBaseChannel("wecom", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
 
 	return &WeComBotChannel{
 		BaseChannel:   base,
@@ -309,8 +309,9 @@ func (c *WeComBotChannel) handleMessageCallback(ctx context.Context, w http.Resp
 		return
 	}
 
-	// Process the message asynchronously with context
-	go c.processMessage(ctx, msg)
+	// Process the message asynchronously. The request context is cancelled
+	// once this handler returns, so the publish must not inherit it.
+	go c.processMessage(context.WithoutCancel(ctx), msg)
 
 	// Return success response immediately
 	// WeCom Bot requires response within configured timeout (default 5 seconds)
@@ -339,14 +340,11 @@ func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessag
 		return
 	}
 	c.processedMsgs[msgID] = true
-	c.msgMu.Unlock()
-
 	// Clean up old messages periodically (keep last 1000)
 	if len(c.processedMsgs) > 1000 {
-		c.msgMu.Lock()
 		c.processedMsgs = make(map[string]bool)
-		c.msgMu.Unlock()
 	}
+	c.msgMu.Unlock()
 
 	senderID := msg.From.UserID
 
@@ -409,7 +407,7 @@ func (c *WeComBotChannel) processMessage(ctx context.Context, msg WeComBotMessag
 	})
 
 	// Handle the message through the base channel
-	c.HandleMessage(senderID, chatID, content, nil, metadata)
+	c.HandleMessage(ctx, senderID, chatID, content, nil, metadata)
 }
 
 // sendWebhookReply sends a reply using the webhook URL
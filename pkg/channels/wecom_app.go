@@ -118,12 +118,12 @@ type WeComSendMessageResponse struct {
 type PKCS7Padding struct{}
 
 // NewWeComAppChannel creates a new WeCom App channel instance
-func NewWeComAppChannel(cfg config.WeComAppConfig, messageBus *bus.MessageBus) (*WeComAppChannel, error) {
+func NewWeComAppChannel(cfg config.WeComAppConfig, messageBus bus.Publisher) (*WeComAppChannel, error) {
 	if cfg.CorpID == "" || cfg.CorpSecret == "" || cfg.AgentID == 0 {
 		return nil, fmt.Errorf("wecom_app corp_id, corp_secret and agent_id are required")
 	}
 
-	base := NewBaseChannel("wecom_app", cfg, messageBus, cfg.AllowFrom)
+	base := NewBaseChannel("wecom_app", cfg, messageBus, cfg.AllowFrom, cfg.BlockFrom)
 
 	return &WeComAppChannel{
 		BaseChannel:   base,
@@ -384,8 +384,9 @@ func (c *WeComAppChannel) handleMessageCallback(ctx context.Context, w http.Resp
 		return
 	}
 
-	// Process the message with context
-	go c.processMessage(ctx, msg)
+	// Process the message asynchronously. The request context is cancelled
+	// once this handler returns, so the publish must not inherit it.
+	go c.processMessage(context.WithoutCancel(ctx), msg)
 
 	// Return success response immediately
 	// WeCom App requires response within configured timeout (default 5 seconds)
@@ -414,14 +415,11 @@ func (c *WeComAppChannel) processMessage(ctx context.Context, msg WeComXMLMessag
 		return
 	}
 	c.processedMsgs[msgID] = true
-	c.msgMu.Unlock()
-
 	// Clean up old messages periodically (keep last 1000)
 	if len(c.processedMsgs) > 1000 {
-		c.msgMu.Lock()
 		c.processedMsgs = make(map[string]bool)
-		c.msgMu.Unlock()
 	}
+	c.msgMu.Unlock()
 
 	senderID := msg.FromUserName
 	chatID := senderID // WeCom App uses user ID as chat ID for direct messages
@@ -448,7 +446,7 @@ func (c *WeComAppChannel) processMessage(ctx context.Context, msg WeComXMLMessag
 	})
 
 	// Handle the message through the base channel
-	c.HandleMessage(senderID, chatID, content, nil, metadata)
+	c.HandleMessage(ctx, senderID, chatID, content, nil, metadata)
 }
 
 // tokenRefreshLoop periodically refreshes the access token
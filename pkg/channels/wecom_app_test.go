@@ -1102,3 +1102,55 @@ func TestWeComAppXMLMessageEvent(t *testing.T) {
 		t.Errorf("EventKey = %q, want %q", msg.EventKey, "event_key_123")
 	}
 }
+
+func TestWeComAppWebhookPublishesAfterResponse(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	spy := bus.NewSpy(msgBus)
+	aesKey := generateTestAESKeyApp()
+	ch, _ := NewWeComAppChannel(config.WeComAppConfig{
+		CorpID:         "test_corp_id",
+		CorpSecret:     "test_secret",
+		AgentID:        1000002,
+		Token:          "test_token",
+		EncodingAESKey: aesKey,
+	}, msgBus)
+
+	// A real server cancels the request context once the handler returns,
+	// before the asynchronous publish runs.
+	srv := httptest.NewServer(http.HandlerFunc(ch.handleWebhook))
+	defer srv.Close()
+
+	xmlData, _ := xml.Marshal(WeComXMLMessage{
+		ToUserName:   "corp_id",
+		FromUserName: "user123",
+		CreateTime:   1234567890,
+		MsgType:      "text",
+		Content:      "Hello World",
+		MsgId:        123456,
+		AgentID:      1000002,
+	})
+	encrypted, _ := encryptTestMessageApp(string(xmlData), aesKey)
+	wrapperData, _ := xml.Marshal(struct {
+		XMLName xml.Name `xml:"xml"`
+		Encrypt string   `xml:"Encrypt"`
+	}{Encrypt: encrypted})
+	signature := generateSignatureApp("test_token", "1234567890", "test_nonce", encrypted)
+
+	resp, err := http.Post(
+		srv.URL+"/webhook/wecom-app?msg_signature="+signature+"&timestamp=1234567890&nonce=test_nonce",
+		"application/xml",
+		bytes.NewReader(wrapperData),
+	)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	msg := spy.AssertInbound(t, 1, 2*time.Second)[0]
+	if msg.SenderID != "user123" || msg.Content != "Hello World" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
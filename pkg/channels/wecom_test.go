@@ -19,6 +19,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -783,3 +784,46 @@ func TestWeComBotMessageStructure(t *testing.T) {
 		t.Errorf("Text.Content = %q, want %q", msg.Text.Content, "Hello World")
 	}
 }
+
+func TestWeComBotWebhookPublishesAfterResponse(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	spy := bus.NewSpy(msgBus)
+	aesKey := generateTestAESKey()
+	ch, _ := NewWeComBotChannel(config.WeComConfig{
+		Token:          "test_token",
+		EncodingAESKey: aesKey,
+		WebhookURL:     "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=test",
+	}, msgBus)
+
+	// A real server cancels the request context once the handler returns,
+	// before the asynchronous publish runs.
+	srv := httptest.NewServer(http.HandlerFunc(ch.handleWebhook))
+	defer srv.Close()
+
+	jsonMsg := `{"msgid":"m1","aibotid":"test_aibot_id","chattype":"single",` +
+		`"from":{"userid":"user123"},"msgtype":"text","text":{"content":"Hello World"}}`
+	encrypted, _ := encryptTestMessage(jsonMsg, aesKey)
+	wrapperData, _ := xml.Marshal(struct {
+		XMLName xml.Name `xml:"xml"`
+		Encrypt string   `xml:"Encrypt"`
+	}{Encrypt: encrypted})
+	signature := generateSignature("test_token", "1234567890", "test_nonce", encrypted)
+
+	resp, err := http.Post(
+		srv.URL+"/webhook/wecom?msg_signature="+signature+"&timestamp=1234567890&nonce=test_nonce",
+		"application/xml",
+		bytes.NewReader(wrapperData),
+	)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	msg := spy.AssertInbound(t, 1, 2*time.Second)[0]
+	if msg.SenderID != "user123" || msg.Content != "Hello World" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
@@ -24,8 +24,8 @@ type WhatsAppChannel struct {
 	connected bool
 }
 
-func NewWhatsAppChannel(cfg config.WhatsAppConfig, bus *bus.MessageBus) (*WhatsAppChannel, error) {
-	base := NewBaseChannel("whatsapp", cfg, bus, cfg.AllowFrom)
+func NewWhatsAppChannel(cfg config.WhatsAppConfig, bus bus.Publisher) (*WhatsAppChannel, error) {
+	base := NewBaseChannel("whatsapp", cfg, bus, cfg.AllowFrom, cfg.BlockFrom)
 
 	return &WhatsAppChannel{
 		BaseChannel: base,
@@ -141,13 +141,13 @@ func (c *WhatsAppChannel) listen(ctx context.Context) {
 			}
 
 			if msgType == "message" {
-				c.handleIncomingMessage(msg)
+				c.handleIncomingMessage(ctx, msg)
 			}
 		}
 	}
 }
 
-func (c *WhatsAppChannel) handleIncomingMessage(msg map[string]any) {
+func (c *WhatsAppChannel) handleIncomingMessage(ctx context.Context, msg map[string]any) {
 	senderID, ok := msg["from"].(string)
 	if !ok {
 		return
@@ -191,5 +191,5 @@ func (c *WhatsAppChannel) handleIncomingMessage(msg map[string]any) {
 
 	log.Printf("WhatsApp message from %s: %s...", senderID, utils.Truncate(content, 50))
 
-	c.HandleMessage(senderID, chatID, content, mediaPaths, metadata)
+	c.HandleMessage(ctx, senderID, chatID, content, mediaPaths, metadata)
 }
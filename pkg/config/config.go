@@ -3,7 +3,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"sync/atomic"
 
 	"github.com/caarlos0/env/v11"
@@ -15,7 +18,8 @@ import (
 var rrCounter atomic.Uint64
 
 // FlexibleStringSlice is a []string that also accepts JSON numbers,
-// so allow_from can contain both "123" and 123.
+// so allow_from can contain both "123" and 123. A single value may be given
+// without the surrounding list.
 type FlexibleStringSlice []string
 
 func (f *FlexibleStringSlice) UnmarshalJSON(data []byte) error {
@@ -29,7 +33,17 @@ func (f *FlexibleStringSlice) UnmarshalJSON(data []byte) error {
 	// Try []interface{} to handle mixed types
 	var raw []any
 	if err := json.Unmarshal(data, &raw); err != nil {
-		return err
+		// Fall back to a single scalar: "alice" or 123
+		var single any
+		if json.Unmarshal(data, &single) != nil {
+			return err
+		}
+		switch single.(type) {
+		case string, float64:
+			raw = []any{single}
+		default:
+			return err
+		}
 	}
 
 	result := make([]string, 0, len(raw))
@@ -47,17 +61,70 @@ func (f *FlexibleStringSlice) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// FlexibleIntOrBool is an int that also accepts numeric strings ("20") and
+// booleans (true = 1, false = 0), for settings users often quote by mistake.
+type FlexibleIntOrBool int
+
+func (f *FlexibleIntOrBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	n, err := flexibleInt(v)
+	if err != nil {
+		return err
+	}
+	*f = FlexibleIntOrBool(n)
+	return nil
+}
+
+// flexibleInt converts a decoded JSON, YAML or TOML scalar to an int.
+func flexibleInt(v any) (int, error) {
+	switch val := v.(type) {
+	case bool:
+		if val {
+			return 1, nil
+		}
+		return 0, nil
+	case int64:
+		return int(val), nil
+	case float64:
+		if val != math.Trunc(val) {
+			return 0, fmt.Errorf("expected an integer, got %v", val)
+		}
+		return int(val), nil
+	case string:
+		s := strings.TrimSpace(val)
+		if n, err := strconv.Atoi(s); err == nil {
+			return n, nil
+		}
+		if b, err := strconv.ParseBool(s); err == nil {
+			return flexibleInt(b)
+		}
+		return 0, fmt.Errorf("expected an integer, got %q", val)
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", v)
+	}
+}
+
 type Config struct {
-	Agents    AgentsConfig    `json:"agents"`
-	Bindings  []AgentBinding  `json:"bindings,omitempty"`
-	Session   SessionConfig   `json:"session,omitempty"`
-	Channels  ChannelsConfig  `json:"channels"`
-	Providers ProvidersConfig `json:"providers,omitempty"`
-	ModelList []ModelConfig   `json:"model_list"` // New model-centric provider configuration
-	Gateway   GatewayConfig   `json:"gateway"`
-	Tools     ToolsConfig     `json:"tools"`
-	Heartbeat HeartbeatConfig `json:"heartbeat"`
-	Devices   DevicesConfig   `json:"devices"`
+	Version   int             `json:"version,omitempty"   yaml:"version,omitempty"   toml:"version,omitempty"   schema:"minimum=1"`
+	Agents    AgentsConfig    `json:"agents"              yaml:"agents"              toml:"agents"`
+	Bindings  []AgentBinding  `json:"bindings,omitempty"  yaml:"bindings,omitempty"  toml:"bindings,omitempty"`
+	Session   SessionConfig   `json:"session,omitempty"   yaml:"session,omitempty"   toml:"session,omitempty"`
+	Channels  ChannelsConfig  `json:"channels"            yaml:"channels"            toml:"channels"`
+	Providers ProvidersConfig `json:"providers,omitempty" yaml:"providers,omitempty" toml:"providers,omitempty"`
+	ModelList []ModelConfig   `json:"model_list"          yaml:"model_list"          toml:"model_list"` // New model-centric provider configuration
+	Gateway   GatewayConfig   `json:"gateway"             yaml:"gateway"             toml:"gateway"`
+	Bus       BusServerConfig `json:"bus"                 yaml:"bus"                 toml:"bus"`
+	Routing   RoutingConfig   `json:"routing"             yaml:"routing"             toml:"routing"`
+	Plugins   PluginsConfig   `json:"plugins"             yaml:"plugins"             toml:"plugins"`
+	Tools     ToolsConfig     `json:"tools"               yaml:"tools"               toml:"tools"`
+	Heartbeat HeartbeatConfig `json:"heartbeat"           yaml:"heartbeat"           toml:"heartbeat"`
+	Devices   DevicesConfig   `json:"devices"             yaml:"devices"             toml:"devices"`
 }
 
 // MarshalJSON implements custom JSON marshaling for Config
@@ -86,16 +153,16 @@ func (c Config) MarshalJSON() ([]byte, error) {
 }
 
 type AgentsConfig struct {
-	Defaults AgentDefaults `json:"defaults"`
-	List     []AgentConfig `json:"list,omitempty"`
+	Defaults AgentDefaults `json:"defaults"       yaml:"defaults"       toml:"defaults"`
+	List     []AgentConfig `json:"list,omitempty" yaml:"list,omitempty" toml:"list,omitempty"`
 }
 
 // AgentModelConfig supports both string and structured model config.
 // String format: "gpt-4" (just primary, no fallbacks)
 // Object format: {"primary": "gpt-4", "fallbacks": ["claude-haiku"]}
 type AgentModelConfig struct {
-	Primary   string   `json:"primary,omitempty"`
-	Fallbacks []string `json:"fallbacks,omitempty"`
+	Primary   string   `json:"primary,omitempty"   yaml:"primary,omitempty"   toml:"primary,omitempty"`
+	Fallbacks []string `json:"fallbacks,omitempty" yaml:"fallbacks,omitempty" toml:"fallbacks,omitempty"`
 }
 
 func (m *AgentModelConfig) UnmarshalJSON(data []byte) error {
@@ -130,55 +197,55 @@ func (m AgentModelConfig) MarshalJSON() ([]byte, error) {
 }
 
 type AgentConfig struct {
-	ID        string            `json:"id"`
-	Default   bool              `json:"default,omitempty"`
-	Name      string            `json:"name,omitempty"`
-	Workspace string            `json:"workspace,omitempty"`
-	Model     *AgentModelConfig `json:"model,omitempty"`
-	Skills    []string          `json:"skills,omitempty"`
-	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+	ID        string            `json:"id"                  yaml:"id"                  toml:"id"`
+	Default   bool              `json:"default,omitempty"   yaml:"default,omitempty"   toml:"default,omitempty"`
+	Name      string            `json:"name,omitempty"      yaml:"name,omitempty"      toml:"name,omitempty"`
+	Workspace string            `json:"workspace,omitempty" yaml:"workspace,omitempty" toml:"workspace,omitempty"`
+	Model     *AgentModelConfig `json:"model,omitempty"     yaml:"model,omitempty"     toml:"model,omitempty"`
+	Skills    []string          `json:"skills,omitempty"    yaml:"skills,omitempty"    toml:"skills,omitempty"`
+	Subagents *SubagentsConfig  `json:"subagents,omitempty" yaml:"subagents,omitempty" toml:"subagents,omitempty"`
 }
 
 type SubagentsConfig struct {
-	AllowAgents []string          `json:"allow_agents,omitempty"`
-	Model       *AgentModelConfig `json:"model,omitempty"`
+	AllowAgents []string          `json:"allow_agents,omitempty" yaml:"allow_agents,omitempty" toml:"allow_agents,omitempty"`
+	Model       *AgentModelConfig `json:"model,omitempty"        yaml:"model,omitempty"        toml:"model,omitempty"`
 }
 
 type PeerMatch struct {
-	Kind string `json:"kind"`
-	ID   string `json:"id"`
+	Kind string `json:"kind" yaml:"kind" toml:"kind"`
+	ID   string `json:"id"   yaml:"id"   toml:"id"`
 }
 
 type BindingMatch struct {
-	Channel   string     `json:"channel"`
-	AccountID string     `json:"account_id,omitempty"`
-	Peer      *PeerMatch `json:"peer,omitempty"`
-	GuildID   string     `json:"guild_id,omitempty"`
-	TeamID    string     `json:"team_id,omitempty"`
+	Channel   string     `json:"channel"              yaml:"channel"              toml:"channel"`
+	AccountID string     `json:"account_id,omitempty" yaml:"account_id,omitempty" toml:"account_id,omitempty"`
+	Peer      *PeerMatch `json:"peer,omitempty"       yaml:"peer,omitempty"       toml:"peer,omitempty"`
+	GuildID   string     `json:"guild_id,omitempty"   yaml:"guild_id,omitempty"   toml:"guild_id,omitempty"`
+	TeamID    string     `json:"team_id,omitempty"    yaml:"team_id,omitempty"    toml:"team_id,omitempty"`
 }
 
 type AgentBinding struct {
-	AgentID string       `json:"agent_id"`
-	Match   BindingMatch `json:"match"`
+	AgentID string       `json:"agent_id" yaml:"agent_id" toml:"agent_id"`
+	Match   BindingMatch `json:"match"    yaml:"match"    toml:"match"`
 }
 
 type SessionConfig struct {
-	DMScope       string              `json:"dm_scope,omitempty"`
-	IdentityLinks map[string][]string `json:"identity_links,omitempty"`
+	DMScope       string              `json:"dm_scope,omitempty"       yaml:"dm_scope,omitempty"       toml:"dm_scope,omitempty"       schema:"enum=main|per-peer|per-channel-peer|per-account-channel-peer"`
+	IdentityLinks map[string][]string `json:"identity_links,omitempty" yaml:"identity_links,omitempty" toml:"identity_links,omitempty"`
 }
 
 type AgentDefaults struct {
-	Workspace           string   `json:"workspace"                       env:"PICOCLAW_AGENTS_DEFAULTS_WORKSPACE"`
-	RestrictToWorkspace bool     `json:"restrict_to_workspace"           env:"PICOCLAW_AGENTS_DEFAULTS_RESTRICT_TO_WORKSPACE"`
-	Provider            string   `json:"provider"                        env:"PICOCLAW_AGENTS_DEFAULTS_PROVIDER"`
-	ModelName           string   `json:"model_name,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_MODEL_NAME"`
-	Model               string   `json:"model,omitempty"                 env:"PICOCLAW_AGENTS_DEFAULTS_MODEL"` // Deprecated: use model_name instead
-	ModelFallbacks      []string `json:"model_fallbacks,omitempty"`
-	ImageModel          string   `json:"image_model,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_IMAGE_MODEL"`
-	ImageModelFallbacks []string `json:"image_model_fallbacks,omitempty"`
-	MaxTokens           int      `json:"max_tokens"                      env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
-	Temperature         *float64 `json:"temperature,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
-	MaxToolIterations   int      `json:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	Workspace           string   `json:"workspace"                       yaml:"workspace"                       toml:"workspace"                       env:"PICOCLAW_AGENTS_DEFAULTS_WORKSPACE"`
+	RestrictToWorkspace bool     `json:"restrict_to_workspace"           yaml:"restrict_to_workspace"           toml:"restrict_to_workspace"           env:"PICOCLAW_AGENTS_DEFAULTS_RESTRICT_TO_WORKSPACE"`
+	Provider            string   `json:"provider"                        yaml:"provider"                        toml:"provider"                        env:"PICOCLAW_AGENTS_DEFAULTS_PROVIDER"`
+	ModelName           string   `json:"model_name,omitempty"            yaml:"model_name,omitempty"            toml:"model_name,omitempty"            env:"PICOCLAW_AGENTS_DEFAULTS_MODEL_NAME"`
+	Model               string   `json:"model,omitempty"                 yaml:"model,omitempty"                 toml:"model,omitempty"                 env:"PICOCLAW_AGENTS_DEFAULTS_MODEL"` // Deprecated: use model_name instead
+	ModelFallbacks      []string `json:"model_fallbacks,omitempty"       yaml:"model_fallbacks,omitempty"       toml:"model_fallbacks,omitempty"`
+	ImageModel          string   `json:"image_model,omitempty"           yaml:"image_model,omitempty"           toml:"image_model,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_IMAGE_MODEL"`
+	ImageModelFallbacks []string `json:"image_model_fallbacks,omitempty" yaml:"image_model_fallbacks,omitempty" toml:"image_model_fallbacks,omitempty"`
+	MaxTokens           int      `json:"max_tokens"                      yaml:"max_tokens"                      toml:"max_tokens"                      env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
+	Temperature         *float64 `json:"temperature,omitempty"           yaml:"temperature,omitempty"           toml:"temperature,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
+	MaxToolIterations   int      `json:"max_tool_iterations"             yaml:"max_tool_iterations"             toml:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
 }
 
 // GetModelName returns the effective model name for the agent defaults.
@@ -191,151 +258,229 @@ func (d *AgentDefaults) GetModelName() string {
 }
 
 type ChannelsConfig struct {
-	WhatsApp WhatsAppConfig `json:"whatsapp"`
-	Telegram TelegramConfig `json:"telegram"`
-	Feishu   FeishuConfig   `json:"feishu"`
-	Discord  DiscordConfig  `json:"discord"`
-	MaixCam  MaixCamConfig  `json:"maixcam"`
-	QQ       QQConfig       `json:"qq"`
-	DingTalk DingTalkConfig `json:"dingtalk"`
-	Slack    SlackConfig    `json:"slack"`
-	LINE     LINEConfig     `json:"line"`
-	OneBot   OneBotConfig   `json:"onebot"`
-	WeCom    WeComConfig    `json:"wecom"`
-	WeComApp WeComAppConfig `json:"wecom_app"`
+	WhatsApp          WhatsAppConfig       `json:"whatsapp"           yaml:"whatsapp"           toml:"whatsapp"`
+	Telegram          TelegramConfig       `json:"telegram"           yaml:"telegram"           toml:"telegram"`
+	Feishu            FeishuConfig         `json:"feishu"             yaml:"feishu"             toml:"feishu"`
+	Discord           DiscordConfig        `json:"discord"            yaml:"discord"            toml:"discord"`
+	MaixCam           MaixCamConfig        `json:"maixcam"            yaml:"maixcam"            toml:"maixcam"`
+	QQ                QQConfig             `json:"qq"                 yaml:"qq"                 toml:"qq"`
+	DingTalk          DingTalkConfig       `json:"dingtalk"           yaml:"dingtalk"           toml:"dingtalk"`
+	Slack             SlackConfig          `json:"slack"              yaml:"slack"              toml:"slack"`
+	LINE              LINEConfig           `json:"line"               yaml:"line"               toml:"line"`
+	OneBot            OneBotConfig         `json:"onebot"             yaml:"onebot"             toml:"onebot"`
+	WeCom             WeComConfig          `json:"wecom"              yaml:"wecom"              toml:"wecom"`
+	WeComApp          WeComAppConfig       `json:"wecom_app"          yaml:"wecom_app"          toml:"wecom_app"`
+	TwitchEventSub    TwitchEventSubConfig `json:"twitch_eventsub"    yaml:"twitch_eventsub"    toml:"twitch_eventsub"`
+	Twicas            TwicasConfig         `json:"twicas"             yaml:"twicas"             toml:"twicas"`
+	NicoNicoTimeshift TimeshiftConfig      `json:"niconico_timeshift" yaml:"niconico_timeshift" toml:"niconico_timeshift"`
+	LineLive          LineLiveConfig       `json:"linelive"           yaml:"linelive"           toml:"linelive"`
+	Notion            NotionConfig         `json:"notion"             yaml:"notion"             toml:"notion"`
 }
 
 type WhatsAppConfig struct {
-	Enabled   bool                `json:"enabled"    env:"PICOCLAW_CHANNELS_WHATSAPP_ENABLED"`
-	BridgeURL string              `json:"bridge_url" env:"PICOCLAW_CHANNELS_WHATSAPP_BRIDGE_URL"`
-	AllowFrom FlexibleStringSlice `json:"allow_from" env:"PICOCLAW_CHANNELS_WHATSAPP_ALLOW_FROM"`
+	Enabled   bool                `json:"enabled"    yaml:"enabled"    toml:"enabled"    env:"PICOCLAW_CHANNELS_WHATSAPP_ENABLED"`
+	BridgeURL string              `json:"bridge_url" yaml:"bridge_url" toml:"bridge_url" env:"PICOCLAW_CHANNELS_WHATSAPP_BRIDGE_URL"`
+	AllowFrom FlexibleStringSlice `json:"allow_from" yaml:"allow_from" toml:"allow_from" env:"PICOCLAW_CHANNELS_WHATSAPP_ALLOW_FROM"`
+	BlockFrom FlexibleStringSlice `json:"block_from" yaml:"block_from" toml:"block_from" env:"PICOCLAW_CHANNELS_WHATSAPP_BLOCK_FROM"`
 }
 
 type TelegramConfig struct {
-	Enabled   bool                `json:"enabled"    env:"PICOCLAW_CHANNELS_TELEGRAM_ENABLED"`
-	Token     string              `json:"token"      env:"PICOCLAW_CHANNELS_TELEGRAM_TOKEN"`
-	Proxy     string              `json:"proxy"      env:"PICOCLAW_CHANNELS_TELEGRAM_PROXY"`
-	AllowFrom FlexibleStringSlice `json:"allow_from" env:"PICOCLAW_CHANNELS_TELEGRAM_ALLOW_FROM"`
+	Enabled   bool                `json:"enabled"    yaml:"enabled"    toml:"enabled"    env:"PICOCLAW_CHANNELS_TELEGRAM_ENABLED"`
+	Token     string              `json:"token"      yaml:"token"      toml:"token"      env:"PICOCLAW_CHANNELS_TELEGRAM_TOKEN"      config:"secret"`
+	Proxy     string              `json:"proxy"      yaml:"proxy"      toml:"proxy"      env:"PICOCLAW_CHANNELS_TELEGRAM_PROXY"`
+	AllowFrom FlexibleStringSlice `json:"allow_from" yaml:"allow_from" toml:"allow_from" env:"PICOCLAW_CHANNELS_TELEGRAM_ALLOW_FROM"`
+	BlockFrom FlexibleStringSlice `json:"block_from" yaml:"block_from" toml:"block_from" env:"PICOCLAW_CHANNELS_TELEGRAM_BLOCK_FROM"`
 }
 
 type FeishuConfig struct {
-	Enabled           bool                `json:"enabled"            env:"PICOCLAW_CHANNELS_FEISHU_ENABLED"`
-	AppID             string              `json:"app_id"             env:"PICOCLAW_CHANNELS_FEISHU_APP_ID"`
-	AppSecret         string              `json:"app_secret"         env:"PICOCLAW_CHANNELS_FEISHU_APP_SECRET"`
-	EncryptKey        string              `json:"encrypt_key"        env:"PICOCLAW_CHANNELS_FEISHU_ENCRYPT_KEY"`
-	VerificationToken string              `json:"verification_token" env:"PICOCLAW_CHANNELS_FEISHU_VERIFICATION_TOKEN"`
-	AllowFrom         FlexibleStringSlice `json:"allow_from"         env:"PICOCLAW_CHANNELS_FEISHU_ALLOW_FROM"`
+	Enabled           bool                `json:"enabled"            yaml:"enabled"            toml:"enabled"            env:"PICOCLAW_CHANNELS_FEISHU_ENABLED"`
+	AppID             string              `json:"app_id"             yaml:"app_id"             toml:"app_id"             env:"PICOCLAW_CHANNELS_FEISHU_APP_ID"`
+	AppSecret         string              `json:"app_secret"         yaml:"app_secret"         toml:"app_secret"         env:"PICOCLAW_CHANNELS_FEISHU_APP_SECRET"         config:"secret"`
+	EncryptKey        string              `json:"encrypt_key"        yaml:"encrypt_key"        toml:"encrypt_key"        env:"PICOCLAW_CHANNELS_FEISHU_ENCRYPT_KEY"        config:"secret"`
+	VerificationToken string              `json:"verification_token" yaml:"verification_token" toml:"verification_token" env:"PICOCLAW_CHANNELS_FEISHU_VERIFICATION_TOKEN" config:"secret"`
+	AllowFrom         FlexibleStringSlice `json:"allow_from"         yaml:"allow_from"         toml:"allow_from"         env:"PICOCLAW_CHANNELS_FEISHU_ALLOW_FROM"`
+	BlockFrom         FlexibleStringSlice `json:"block_from"         yaml:"block_from"         toml:"block_from"         env:"PICOCLAW_CHANNELS_FEISHU_BLOCK_FROM"`
 }
 
 type DiscordConfig struct {
-	Enabled     bool                `json:"enabled"      env:"PICOCLAW_CHANNELS_DISCORD_ENABLED"`
-	Token       string              `json:"token"        env:"PICOCLAW_CHANNELS_DISCORD_TOKEN"`
-	AllowFrom   FlexibleStringSlice `json:"allow_from"   env:"PICOCLAW_CHANNELS_DISCORD_ALLOW_FROM"`
-	MentionOnly bool                `json:"mention_only" env:"PICOCLAW_CHANNELS_DISCORD_MENTION_ONLY"`
+	Enabled     bool                `json:"enabled"      yaml:"enabled"      toml:"enabled"      env:"PICOCLAW_CHANNELS_DISCORD_ENABLED"`
+	Token       string              `json:"token"        yaml:"token"        toml:"token"        env:"PICOCLAW_CHANNELS_DISCORD_TOKEN"        config:"secret"`
+	AllowFrom   FlexibleStringSlice `json:"allow_from"   yaml:"allow_from"   toml:"allow_from"   env:"PICOCLAW_CHANNELS_DISCORD_ALLOW_FROM"`
+	BlockFrom   FlexibleStringSlice `json:"block_from"   yaml:"block_from"   toml:"block_from"   env:"PICOCLAW_CHANNELS_DISCORD_BLOCK_FROM"`
+	MentionOnly bool                `json:"mention_only" yaml:"mention_only" toml:"mention_only" env:"PICOCLAW_CHANNELS_DISCORD_MENTION_ONLY"`
 }
 
 type MaixCamConfig struct {
-	Enabled   bool                `json:"enabled"    env:"PICOCLAW_CHANNELS_MAIXCAM_ENABLED"`
-	Host      string              `json:"host"       env:"PICOCLAW_CHANNELS_MAIXCAM_HOST"`
-	Port      int                 `json:"port"       env:"PICOCLAW_CHANNELS_MAIXCAM_PORT"`
-	AllowFrom FlexibleStringSlice `json:"allow_from" env:"PICOCLAW_CHANNELS_MAIXCAM_ALLOW_FROM"`
+	Enabled   bool                `json:"enabled"    yaml:"enabled"    toml:"enabled"    env:"PICOCLAW_CHANNELS_MAIXCAM_ENABLED"`
+	Host      string              `json:"host"       yaml:"host"       toml:"host"       env:"PICOCLAW_CHANNELS_MAIXCAM_HOST"`
+	Port      int                 `json:"port"       yaml:"port"       toml:"port"       env:"PICOCLAW_CHANNELS_MAIXCAM_PORT"`
+	AllowFrom FlexibleStringSlice `json:"allow_from" yaml:"allow_from" toml:"allow_from" env:"PICOCLAW_CHANNELS_MAIXCAM_ALLOW_FROM"`
+	BlockFrom FlexibleStringSlice `json:"block_from" yaml:"block_from" toml:"block_from" env:"PICOCLAW_CHANNELS_MAIXCAM_BLOCK_FROM"`
 }
 
 type QQConfig struct {
-	Enabled   bool                `json:"enabled"    env:"PICOCLAW_CHANNELS_QQ_ENABLED"`
-	AppID     string              `json:"app_id"     env:"PICOCLAW_CHANNELS_QQ_APP_ID"`
-	AppSecret string              `json:"app_secret" env:"PICOCLAW_CHANNELS_QQ_APP_SECRET"`
-	AllowFrom FlexibleStringSlice `json:"allow_from" env:"PICOCLAW_CHANNELS_QQ_ALLOW_FROM"`
+	Enabled   bool                `json:"enabled"    yaml:"enabled"    toml:"enabled"    env:"PICOCLAW_CHANNELS_QQ_ENABLED"`
+	AppID     string              `json:"app_id"     yaml:"app_id"     toml:"app_id"     env:"PICOCLAW_CHANNELS_QQ_APP_ID"`
+	AppSecret string              `json:"app_secret" yaml:"app_secret" toml:"app_secret" env:"PICOCLAW_CHANNELS_QQ_APP_SECRET" config:"secret"`
+	AllowFrom FlexibleStringSlice `json:"allow_from" yaml:"allow_from" toml:"allow_from" env:"PICOCLAW_CHANNELS_QQ_ALLOW_FROM"`
+	BlockFrom FlexibleStringSlice `json:"block_from" yaml:"block_from" toml:"block_from" env:"PICOCLAW_CHANNELS_QQ_BLOCK_FROM"`
 }
 
 type DingTalkConfig struct {
-	Enabled      bool                `json:"enabled"       env:"PICOCLAW_CHANNELS_DINGTALK_ENABLED"`
-	ClientID     string              `json:"client_id"     env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_ID"`
-	ClientSecret string              `json:"client_secret" env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_SECRET"`
-	AllowFrom    FlexibleStringSlice `json:"allow_from"    env:"PICOCLAW_CHANNELS_DINGTALK_ALLOW_FROM"`
+	Enabled      bool                `json:"enabled"       yaml:"enabled"       toml:"enabled"       env:"PICOCLAW_CHANNELS_DINGTALK_ENABLED"`
+	ClientID     string              `json:"client_id"     yaml:"client_id"     toml:"client_id"     env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_ID"`
+	ClientSecret string              `json:"client_secret" yaml:"client_secret" toml:"client_secret" env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_SECRET" config:"secret"`
+	AllowFrom    FlexibleStringSlice `json:"allow_from"    yaml:"allow_from"    toml:"allow_from"    env:"PICOCLAW_CHANNELS_DINGTALK_ALLOW_FROM"`
+	BlockFrom    FlexibleStringSlice `json:"block_from"    yaml:"block_from"    toml:"block_from"    env:"PICOCLAW_CHANNELS_DINGTALK_BLOCK_FROM"`
 }
 
 type SlackConfig struct {
-	Enabled   bool                `json:"enabled"    env:"PICOCLAW_CHANNELS_SLACK_ENABLED"`
-	BotToken  string              `json:"bot_token"  env:"PICOCLAW_CHANNELS_SLACK_BOT_TOKEN"`
-	AppToken  string              `json:"app_token"  env:"PICOCLAW_CHANNELS_SLACK_APP_TOKEN"`
-	AllowFrom FlexibleStringSlice `json:"allow_from" env:"PICOCLAW_CHANNELS_SLACK_ALLOW_FROM"`
+	Enabled       bool                `json:"enabled"        yaml:"enabled"        toml:"enabled"        env:"PICOCLAW_CHANNELS_SLACK_ENABLED"`
+	BotToken      string              `json:"bot_token"      yaml:"bot_token"      toml:"bot_token"      env:"PICOCLAW_CHANNELS_SLACK_BOT_TOKEN"      config:"secret"`
+	AppToken      string              `json:"app_token"      yaml:"app_token"      toml:"app_token"      env:"PICOCLAW_CHANNELS_SLACK_APP_TOKEN"      config:"secret"`
+	SigningSecret string              `json:"signing_secret" yaml:"signing_secret" toml:"signing_secret" env:"PICOCLAW_CHANNELS_SLACK_SIGNING_SECRET" config:"secret"` // Events API mode, used when app_token is empty
+	ChannelID     string              `json:"channel_id"     yaml:"channel_id"     toml:"channel_id"     env:"PICOCLAW_CHANNELS_SLACK_CHANNEL_ID"`                     // optional: only accept events from this channel
+	ListenPath    string              `json:"listen_path"    yaml:"listen_path"    toml:"listen_path"    env:"PICOCLAW_CHANNELS_SLACK_LISTEN_PATH"`
+	ListenPort    int                 `json:"listen_port"    yaml:"listen_port"    toml:"listen_port"    env:"PICOCLAW_CHANNELS_SLACK_LISTEN_PORT"`
+	AllowFrom     FlexibleStringSlice `json:"allow_from"     yaml:"allow_from"     toml:"allow_from"     env:"PICOCLAW_CHANNELS_SLACK_ALLOW_FROM"`
+	BlockFrom     FlexibleStringSlice `json:"block_from"     yaml:"block_from"     toml:"block_from"     env:"PICOCLAW_CHANNELS_SLACK_BLOCK_FROM"`
 }
 
 type LINEConfig struct {
-	Enabled            bool                `json:"enabled"              env:"PICOCLAW_CHANNELS_LINE_ENABLED"`
-	ChannelSecret      string              `json:"channel_secret"       env:"PICOCLAW_CHANNELS_LINE_CHANNEL_SECRET"`
-	ChannelAccessToken string              `json:"channel_access_token" env:"PICOCLAW_CHANNELS_LINE_CHANNEL_ACCESS_TOKEN"`
-	WebhookHost        string              `json:"webhook_host"         env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_HOST"`
-	WebhookPort        int                 `json:"webhook_port"         env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PORT"`
-	WebhookPath        string              `json:"webhook_path"         env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PATH"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"           env:"PICOCLAW_CHANNELS_LINE_ALLOW_FROM"`
+	Enabled            bool                `json:"enabled"              yaml:"enabled"              toml:"enabled"              env:"PICOCLAW_CHANNELS_LINE_ENABLED"`
+	ChannelSecret      string              `json:"channel_secret"       yaml:"channel_secret"       toml:"channel_secret"       env:"PICOCLAW_CHANNELS_LINE_CHANNEL_SECRET"       config:"secret"`
+	ChannelAccessToken string              `json:"channel_access_token" yaml:"channel_access_token" toml:"channel_access_token" env:"PICOCLAW_CHANNELS_LINE_CHANNEL_ACCESS_TOKEN" config:"secret"`
+	WebhookHost        string              `json:"webhook_host"         yaml:"webhook_host"         toml:"webhook_host"         env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_HOST"`
+	WebhookPort        int                 `json:"webhook_port"         yaml:"webhook_port"         toml:"webhook_port"         env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PORT"`
+	WebhookPath        string              `json:"webhook_path"         yaml:"webhook_path"         toml:"webhook_path"         env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PATH"`
+	AllowFrom          FlexibleStringSlice `json:"allow_from"           yaml:"allow_from"           toml:"allow_from"           env:"PICOCLAW_CHANNELS_LINE_ALLOW_FROM"`
+	BlockFrom          FlexibleStringSlice `json:"block_from"           yaml:"block_from"           toml:"block_from"           env:"PICOCLAW_CHANNELS_LINE_BLOCK_FROM"`
 }
 
 type OneBotConfig struct {
-	Enabled            bool                `json:"enabled"              env:"PICOCLAW_CHANNELS_ONEBOT_ENABLED"`
-	WSUrl              string              `json:"ws_url"               env:"PICOCLAW_CHANNELS_ONEBOT_WS_URL"`
-	AccessToken        string              `json:"access_token"         env:"PICOCLAW_CHANNELS_ONEBOT_ACCESS_TOKEN"`
-	ReconnectInterval  int                 `json:"reconnect_interval"   env:"PICOCLAW_CHANNELS_ONEBOT_RECONNECT_INTERVAL"`
-	GroupTriggerPrefix []string            `json:"group_trigger_prefix" env:"PICOCLAW_CHANNELS_ONEBOT_GROUP_TRIGGER_PREFIX"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"           env:"PICOCLAW_CHANNELS_ONEBOT_ALLOW_FROM"`
+	Enabled            bool                `json:"enabled"              yaml:"enabled"              toml:"enabled"              env:"PICOCLAW_CHANNELS_ONEBOT_ENABLED"`
+	WSUrl              string              `json:"ws_url"               yaml:"ws_url"               toml:"ws_url"               env:"PICOCLAW_CHANNELS_ONEBOT_WS_URL"`
+	AccessToken        string              `json:"access_token"         yaml:"access_token"         toml:"access_token"         env:"PICOCLAW_CHANNELS_ONEBOT_ACCESS_TOKEN"         config:"secret"`
+	ReconnectInterval  FlexibleIntOrBool   `json:"reconnect_interval"   yaml:"reconnect_interval"   toml:"reconnect_interval"   env:"PICOCLAW_CHANNELS_ONEBOT_RECONNECT_INTERVAL"`
+	GroupTriggerPrefix []string            `json:"group_trigger_prefix" yaml:"group_trigger_prefix" toml:"group_trigger_prefix" env:"PICOCLAW_CHANNELS_ONEBOT_GROUP_TRIGGER_PREFIX"`
+	AllowFrom          FlexibleStringSlice `json:"allow_from"           yaml:"allow_from"           toml:"allow_from"           env:"PICOCLAW_CHANNELS_ONEBOT_ALLOW_FROM"`
+	BlockFrom          FlexibleStringSlice `json:"block_from"           yaml:"block_from"           toml:"block_from"           env:"PICOCLAW_CHANNELS_ONEBOT_BLOCK_FROM"`
+}
+
+// TwitchEventSubConfig configures the Twitch EventSub WebSocket channel, which
+// delivers follow and subscription events (not chat) for one broadcaster.
+type TwitchEventSubConfig struct {
+	Enabled           bool                `json:"enabled"             yaml:"enabled"             toml:"enabled"             env:"PICOCLAW_CHANNELS_TWITCH_EVENTSUB_ENABLED"`
+	ClientID          string              `json:"client_id"           yaml:"client_id"           toml:"client_id"           env:"PICOCLAW_CHANNELS_TWITCH_EVENTSUB_CLIENT_ID"`
+	UserAccessToken   string              `json:"user_access_token"   yaml:"user_access_token"   toml:"user_access_token"   env:"PICOCLAW_CHANNELS_TWITCH_EVENTSUB_USER_ACCESS_TOKEN"   config:"secret"`
+	BroadcasterUserID string              `json:"broadcaster_user_id" yaml:"broadcaster_user_id" toml:"broadcaster_user_id" env:"PICOCLAW_CHANNELS_TWITCH_EVENTSUB_BROADCASTER_USER_ID"`
+	AllowFrom         FlexibleStringSlice `json:"allow_from"          yaml:"allow_from"          toml:"allow_from"          env:"PICOCLAW_CHANNELS_TWITCH_EVENTSUB_ALLOW_FROM"`
+	BlockFrom         FlexibleStringSlice `json:"block_from"          yaml:"block_from"          toml:"block_from"          env:"PICOCLAW_CHANNELS_TWITCH_EVENTSUB_BLOCK_FROM"`
+}
+
+// TwicasConfig configures the TwitCasting channel, which polls the comments
+// of one live movie. Comments containing any NGWords entry are dropped.
+type TwicasConfig struct {
+	Enabled             bool                `json:"enabled"               yaml:"enabled"               toml:"enabled"               env:"PICOCLAW_CHANNELS_TWICAS_ENABLED"`
+	MovieID             string              `json:"movie_id"              yaml:"movie_id"              toml:"movie_id"              env:"PICOCLAW_CHANNELS_TWICAS_MOVIE_ID"`
+	AccessToken         string              `json:"access_token"          yaml:"access_token"          toml:"access_token"          env:"PICOCLAW_CHANNELS_TWICAS_ACCESS_TOKEN"          config:"secret"`
+	PollIntervalSeconds int                 `json:"poll_interval_seconds" yaml:"poll_interval_seconds" toml:"poll_interval_seconds" env:"PICOCLAW_CHANNELS_TWICAS_POLL_INTERVAL_SECONDS" schema:"minimum=1"`
+	MessageFormat       string              `json:"message_format"        yaml:"message_format"        toml:"message_format"        env:"PICOCLAW_CHANNELS_TWICAS_MESSAGE_FORMAT"`
+	NGWords             FlexibleStringSlice `json:"ng_words"              yaml:"ng_words"              toml:"ng_words"              env:"PICOCLAW_CHANNELS_TWICAS_NG_WORDS"`
+	AllowFrom           FlexibleStringSlice `json:"allow_from"            yaml:"allow_from"            toml:"allow_from"            env:"PICOCLAW_CHANNELS_TWICAS_ALLOW_FROM"`
+	BlockFrom           FlexibleStringSlice `json:"block_from"            yaml:"block_from"            toml:"block_from"            env:"PICOCLAW_CHANNELS_TWICAS_BLOCK_FROM"`
+}
+
+// LineLiveConfig configures the LINE LIVE channel, which reads chat comments
+// of one broadcast from the LINE LIVE comments WebSocket.
+type LineLiveConfig struct {
+	Enabled       bool                `json:"enabled"        yaml:"enabled"        toml:"enabled"        env:"PICOCLAW_CHANNELS_LINELIVE_ENABLED"`
+	BroadcastID   string              `json:"broadcast_id"   yaml:"broadcast_id"   toml:"broadcast_id"   env:"PICOCLAW_CHANNELS_LINELIVE_BROADCAST_ID"`
+	AccessToken   string              `json:"access_token"   yaml:"access_token"   toml:"access_token"   env:"PICOCLAW_CHANNELS_LINELIVE_ACCESS_TOKEN"   config:"secret"`
+	MessageFormat string              `json:"message_format" yaml:"message_format" toml:"message_format" env:"PICOCLAW_CHANNELS_LINELIVE_MESSAGE_FORMAT"`
+	NGWords       FlexibleStringSlice `json:"ng_words"       yaml:"ng_words"       toml:"ng_words"       env:"PICOCLAW_CHANNELS_LINELIVE_NG_WORDS"`
+	AllowFrom     FlexibleStringSlice `json:"allow_from"     yaml:"allow_from"     toml:"allow_from"     env:"PICOCLAW_CHANNELS_LINELIVE_ALLOW_FROM"`
+	BlockFrom     FlexibleStringSlice `json:"block_from"     yaml:"block_from"     toml:"block_from"     env:"PICOCLAW_CHANNELS_LINELIVE_BLOCK_FROM"`
+}
+
+// TimeshiftConfig configures the NicoNico timeshift channel, which replays
+// the archived comments of ContentID at their original timing, sped up by
+// PlaybackSpeed (1.0 = real time, the default).
+type TimeshiftConfig struct {
+	Enabled       bool                `json:"enabled"        yaml:"enabled"        toml:"enabled"        env:"PICOCLAW_CHANNELS_NICONICO_TIMESHIFT_ENABLED"`
+	ContentID     string              `json:"content_id"     yaml:"content_id"     toml:"content_id"     env:"PICOCLAW_CHANNELS_NICONICO_TIMESHIFT_CONTENT_ID"`
+	UserSession   string              `json:"user_session"   yaml:"user_session"   toml:"user_session"   env:"PICOCLAW_CHANNELS_NICONICO_TIMESHIFT_USER_SESSION"   config:"secret"`
+	PlaybackSpeed float64             `json:"playback_speed" yaml:"playback_speed" toml:"playback_speed" env:"PICOCLAW_CHANNELS_NICONICO_TIMESHIFT_PLAYBACK_SPEED"`
+	AllowFrom     FlexibleStringSlice `json:"allow_from"     yaml:"allow_from"     toml:"allow_from"     env:"PICOCLAW_CHANNELS_NICONICO_TIMESHIFT_ALLOW_FROM"`
+	BlockFrom     FlexibleStringSlice `json:"block_from"     yaml:"block_from"     toml:"block_from"     env:"PICOCLAW_CHANNELS_NICONICO_TIMESHIFT_BLOCK_FROM"`
+}
+
+// NotionConfig configures the outbound-only Notion channel, which records
+// each message sent to it as a row in a Notion database.
+type NotionConfig struct {
+	Enabled          bool   `json:"enabled"           yaml:"enabled"           toml:"enabled"           env:"PICOCLAW_CHANNELS_NOTION_ENABLED"`
+	IntegrationToken string `json:"integration_token" yaml:"integration_token" toml:"integration_token" env:"PICOCLAW_CHANNELS_NOTION_INTEGRATION_TOKEN" config:"secret"`
+	DatabaseID       string `json:"database_id"       yaml:"database_id"       toml:"database_id"       env:"PICOCLAW_CHANNELS_NOTION_DATABASE_ID"`
+	TitleProperty    string `json:"title_property"    yaml:"title_property"    toml:"title_property"    env:"PICOCLAW_CHANNELS_NOTION_TITLE_PROPERTY"`
 }
 
 type WeComConfig struct {
-	Enabled        bool                `json:"enabled"          env:"PICOCLAW_CHANNELS_WECOM_ENABLED"`
-	Token          string              `json:"token"            env:"PICOCLAW_CHANNELS_WECOM_TOKEN"`
-	EncodingAESKey string              `json:"encoding_aes_key" env:"PICOCLAW_CHANNELS_WECOM_ENCODING_AES_KEY"`
-	WebhookURL     string              `json:"webhook_url"      env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_URL"`
-	WebhookHost    string              `json:"webhook_host"     env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_HOST"`
-	WebhookPort    int                 `json:"webhook_port"     env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PORT"`
-	WebhookPath    string              `json:"webhook_path"     env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PATH"`
-	AllowFrom      FlexibleStringSlice `json:"allow_from"       env:"PICOCLAW_CHANNELS_WECOM_ALLOW_FROM"`
-	ReplyTimeout   int                 `json:"reply_timeout"    env:"PICOCLAW_CHANNELS_WECOM_REPLY_TIMEOUT"`
+	Enabled        bool                `json:"enabled"          yaml:"enabled"          toml:"enabled"          env:"PICOCLAW_CHANNELS_WECOM_ENABLED"`
+	Token          string              `json:"token"            yaml:"token"            toml:"token"            env:"PICOCLAW_CHANNELS_WECOM_TOKEN"            config:"secret"`
+	EncodingAESKey string              `json:"encoding_aes_key" yaml:"encoding_aes_key" toml:"encoding_aes_key" env:"PICOCLAW_CHANNELS_WECOM_ENCODING_AES_KEY" config:"secret"`
+	WebhookURL     string              `json:"webhook_url"      yaml:"webhook_url"      toml:"webhook_url"      env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_URL"`
+	WebhookHost    string              `json:"webhook_host"     yaml:"webhook_host"     toml:"webhook_host"     env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_HOST"`
+	WebhookPort    int                 `json:"webhook_port"     yaml:"webhook_port"     toml:"webhook_port"     env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PORT"`
+	WebhookPath    string              `json:"webhook_path"     yaml:"webhook_path"     toml:"webhook_path"     env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PATH"`
+	AllowFrom      FlexibleStringSlice `json:"allow_from"       yaml:"allow_from"       toml:"allow_from"       env:"PICOCLAW_CHANNELS_WECOM_ALLOW_FROM"`
+	BlockFrom      FlexibleStringSlice `json:"block_from"       yaml:"block_from"       toml:"block_from"       env:"PICOCLAW_CHANNELS_WECOM_BLOCK_FROM"`
+	ReplyTimeout   int                 `json:"reply_timeout"    yaml:"reply_timeout"    toml:"reply_timeout"    env:"PICOCLAW_CHANNELS_WECOM_REPLY_TIMEOUT"`
 }
 
 type WeComAppConfig struct {
-	Enabled        bool                `json:"enabled"          env:"PICOCLAW_CHANNELS_WECOM_APP_ENABLED"`
-	CorpID         string              `json:"corp_id"          env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_ID"`
-	CorpSecret     string              `json:"corp_secret"      env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_SECRET"`
-	AgentID        int64               `json:"agent_id"         env:"PICOCLAW_CHANNELS_WECOM_APP_AGENT_ID"`
-	Token          string              `json:"token"            env:"PICOCLAW_CHANNELS_WECOM_APP_TOKEN"`
-	EncodingAESKey string              `json:"encoding_aes_key" env:"PICOCLAW_CHANNELS_WECOM_APP_ENCODING_AES_KEY"`
-	WebhookHost    string              `json:"webhook_host"     env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_HOST"`
-	WebhookPort    int                 `json:"webhook_port"     env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PORT"`
-	WebhookPath    string              `json:"webhook_path"     env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PATH"`
-	AllowFrom      FlexibleStringSlice `json:"allow_from"       env:"PICOCLAW_CHANNELS_WECOM_APP_ALLOW_FROM"`
-	ReplyTimeout   int                 `json:"reply_timeout"    env:"PICOCLAW_CHANNELS_WECOM_APP_REPLY_TIMEOUT"`
+	Enabled        bool                `json:"enabled"          yaml:"enabled"          toml:"enabled"          env:"PICOCLAW_CHANNELS_WECOM_APP_ENABLED"`
+	CorpID         string              `json:"corp_id"          yaml:"corp_id"          toml:"corp_id"          env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_ID"`
+	CorpSecret     string              `json:"corp_secret"      yaml:"corp_secret"      toml:"corp_secret"      env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_SECRET"      config:"secret"`
+	AgentID        int64               `json:"agent_id"         yaml:"agent_id"         toml:"agent_id"         env:"PICOCLAW_CHANNELS_WECOM_APP_AGENT_ID"`
+	Token          string              `json:"token"            yaml:"token"            toml:"token"            env:"PICOCLAW_CHANNELS_WECOM_APP_TOKEN"            config:"secret"`
+	EncodingAESKey string              `json:"encoding_aes_key" yaml:"encoding_aes_key" toml:"encoding_aes_key" env:"PICOCLAW_CHANNELS_WECOM_APP_ENCODING_AES_KEY" config:"secret"`
+	WebhookHost    string              `json:"webhook_host"     yaml:"webhook_host"     toml:"webhook_host"     env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_HOST"`
+	WebhookPort    int                 `json:"webhook_port"     yaml:"webhook_port"     toml:"webhook_port"     env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PORT"`
+	WebhookPath    string              `json:"webhook_path"     yaml:"webhook_path"     toml:"webhook_path"     env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PATH"`
+	AllowFrom      FlexibleStringSlice `json:"allow_from"       yaml:"allow_from"       toml:"allow_from"       env:"PICOCLAW_CHANNELS_WECOM_APP_ALLOW_FROM"`
+	BlockFrom      FlexibleStringSlice `json:"block_from"       yaml:"block_from"       toml:"block_from"       env:"PICOCLAW_CHANNELS_WECOM_APP_BLOCK_FROM"`
+	ReplyTimeout   int                 `json:"reply_timeout"    yaml:"reply_timeout"    toml:"reply_timeout"    env:"PICOCLAW_CHANNELS_WECOM_APP_REPLY_TIMEOUT"`
 }
 
 type HeartbeatConfig struct {
-	Enabled  bool `json:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
-	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
+	Enabled  bool              `json:"enabled"  yaml:"enabled"  toml:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
+	Interval FlexibleIntOrBool `json:"interval" yaml:"interval" toml:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL" schema:"minimum=5"` // minutes, min 5
 }
 
 type DevicesConfig struct {
-	Enabled    bool `json:"enabled"     env:"PICOCLAW_DEVICES_ENABLED"`
-	MonitorUSB bool `json:"monitor_usb" env:"PICOCLAW_DEVICES_MONITOR_USB"`
+	Enabled    bool `json:"enabled"     yaml:"enabled"     toml:"enabled"     env:"PICOCLAW_DEVICES_ENABLED"`
+	MonitorUSB bool `json:"monitor_usb" yaml:"monitor_usb" toml:"monitor_usb" env:"PICOCLAW_DEVICES_MONITOR_USB"`
 }
 
 type ProvidersConfig struct {
-	Anthropic     ProviderConfig       `json:"anthropic"`
-	OpenAI        OpenAIProviderConfig `json:"openai"`
-	OpenRouter    ProviderConfig       `json:"openrouter"`
-	Groq          ProviderConfig       `json:"groq"`
-	Zhipu         ProviderConfig       `json:"zhipu"`
-	VLLM          ProviderConfig       `json:"vllm"`
-	Gemini        ProviderConfig       `json:"gemini"`
-	Nvidia        ProviderConfig       `json:"nvidia"`
-	Ollama        ProviderConfig       `json:"ollama"`
-	Moonshot      ProviderConfig       `json:"moonshot"`
-	ShengSuanYun  ProviderConfig       `json:"shengsuanyun"`
-	DeepSeek      ProviderConfig       `json:"deepseek"`
-	Cerebras      ProviderConfig       `json:"cerebras"`
-	VolcEngine    ProviderConfig       `json:"volcengine"`
-	GitHubCopilot ProviderConfig       `json:"github_copilot"`
-	Antigravity   ProviderConfig       `json:"antigravity"`
-	Qwen          ProviderConfig       `json:"qwen"`
-	Mistral       ProviderConfig       `json:"mistral"`
+	Anthropic     ProviderConfig       `json:"anthropic"      yaml:"anthropic"      toml:"anthropic"`
+	OpenAI        OpenAIProviderConfig `json:"openai"         yaml:"openai"         toml:"openai"`
+	OpenRouter    ProviderConfig       `json:"openrouter"     yaml:"openrouter"     toml:"openrouter"`
+	Groq          ProviderConfig       `json:"groq"           yaml:"groq"           toml:"groq"`
+	Zhipu         ProviderConfig       `json:"zhipu"          yaml:"zhipu"          toml:"zhipu"`
+	VLLM          ProviderConfig       `json:"vllm"           yaml:"vllm"           toml:"vllm"`
+	Gemini        ProviderConfig       `json:"gemini"         yaml:"gemini"         toml:"gemini"`
+	Nvidia        ProviderConfig       `json:"nvidia"         yaml:"nvidia"         toml:"nvidia"`
+	Ollama        ProviderConfig       `json:"ollama"         yaml:"ollama"         toml:"ollama"`
+	Moonshot      ProviderConfig       `json:"moonshot"       yaml:"moonshot"       toml:"moonshot"`
+	ShengSuanYun  ProviderConfig       `json:"shengsuanyun"   yaml:"shengsuanyun"   toml:"shengsuanyun"`
+	DeepSeek      ProviderConfig       `json:"deepseek"       yaml:"deepseek"       toml:"deepseek"`
+	Cerebras      ProviderConfig       `json:"cerebras"       yaml:"cerebras"       toml:"cerebras"`
+	VolcEngine    ProviderConfig       `json:"volcengine"     yaml:"volcengine"     toml:"volcengine"`
+	GitHubCopilot ProviderConfig       `json:"github_copilot" yaml:"github_copilot" toml:"github_copilot"`
+	Antigravity   ProviderConfig       `json:"antigravity"    yaml:"antigravity"    toml:"antigravity"`
+	Qwen          ProviderConfig       `json:"qwen"           yaml:"qwen"           toml:"qwen"`
+	Mistral       ProviderConfig       `json:"mistral"        yaml:"mistral"        toml:"mistral"`
 }
 
 // IsEmpty checks if all provider configs are empty (no API keys or API bases set)
@@ -372,17 +517,17 @@ func (p ProvidersConfig) MarshalJSON() ([]byte, error) {
 }
 
 type ProviderConfig struct {
-	APIKey         string `json:"api_key"                   env:"PICOCLAW_PROVIDERS_{{.Name}}_API_KEY"`
-	APIBase        string `json:"api_base"                  env:"PICOCLAW_PROVIDERS_{{.Name}}_API_BASE"`
-	Proxy          string `json:"proxy,omitempty"           env:"PICOCLAW_PROVIDERS_{{.Name}}_PROXY"`
-	RequestTimeout int    `json:"request_timeout,omitempty" env:"PICOCLAW_PROVIDERS_{{.Name}}_REQUEST_TIMEOUT"`
-	AuthMethod     string `json:"auth_method,omitempty"     env:"PICOCLAW_PROVIDERS_{{.Name}}_AUTH_METHOD"`
-	ConnectMode    string `json:"connect_mode,omitempty"    env:"PICOCLAW_PROVIDERS_{{.Name}}_CONNECT_MODE"` // only for Github Copilot, `stdio` or `grpc`
+	APIKey         string `json:"api_key"                   yaml:"api_key"                   toml:"api_key"                   env:"PICOCLAW_PROVIDERS_{{.Name}}_API_KEY"         config:"secret"`
+	APIBase        string `json:"api_base"                  yaml:"api_base"                  toml:"api_base"                  env:"PICOCLAW_PROVIDERS_{{.Name}}_API_BASE"`
+	Proxy          string `json:"proxy,omitempty"           yaml:"proxy,omitempty"           toml:"proxy,omitempty"           env:"PICOCLAW_PROVIDERS_{{.Name}}_PROXY"`
+	RequestTimeout int    `json:"request_timeout,omitempty" yaml:"request_timeout,omitempty" toml:"request_timeout,omitempty" env:"PICOCLAW_PROVIDERS_{{.Name}}_REQUEST_TIMEOUT"`
+	AuthMethod     string `json:"auth_method,omitempty"     yaml:"auth_method,omitempty"     toml:"auth_method,omitempty"     env:"PICOCLAW_PROVIDERS_{{.Name}}_AUTH_METHOD"`
+	ConnectMode    string `json:"connect_mode,omitempty"    yaml:"connect_mode,omitempty"    toml:"connect_mode,omitempty"    env:"PICOCLAW_PROVIDERS_{{.Name}}_CONNECT_MODE"` // only for Github Copilot, `stdio` or `grpc`
 }
 
 type OpenAIProviderConfig struct {
 	ProviderConfig
-	WebSearch bool `json:"web_search" env:"PICOCLAW_PROVIDERS_OPENAI_WEB_SEARCH"`
+	WebSearch bool `json:"web_search" yaml:"web_search" toml:"web_search" env:"PICOCLAW_PROVIDERS_OPENAI_WEB_SEARCH"`
 }
 
 // ModelConfig represents a model-centric provider configuration.
@@ -392,23 +537,23 @@ type OpenAIProviderConfig struct {
 // Default protocol is "openai" if no prefix is specified.
 type ModelConfig struct {
 	// Required fields
-	ModelName string `json:"model_name"` // User-facing alias for the model
-	Model     string `json:"model"`      // Protocol/model-identifier (e.g., "openai/gpt-4o", "anthropic/claude-sonnet-4.6")
+	ModelName string `json:"model_name" yaml:"model_name" toml:"model_name" schema:"required"` // User-facing alias for the model
+	Model     string `json:"model"      yaml:"model"      toml:"model"      schema:"required"` // Protocol/model-identifier (e.g., "openai/gpt-4o", "anthropic/claude-sonnet-4.6")
 
 	// HTTP-based providers
-	APIBase string `json:"api_base,omitempty"` // API endpoint URL
-	APIKey  string `json:"api_key"`            // API authentication key
-	Proxy   string `json:"proxy,omitempty"`    // HTTP proxy URL
+	APIBase string `json:"api_base,omitempty" yaml:"api_base,omitempty" toml:"api_base,omitempty"`                 // API endpoint URL
+	APIKey  string `json:"api_key"            yaml:"api_key"            toml:"api_key"            config:"secret"` // API authentication key
+	Proxy   string `json:"proxy,omitempty"    yaml:"proxy,omitempty"    toml:"proxy,omitempty"`                    // HTTP proxy URL
 
 	// Special providers (CLI-based, OAuth, etc.)
-	AuthMethod  string `json:"auth_method,omitempty"`  // Authentication method: oauth, token
-	ConnectMode string `json:"connect_mode,omitempty"` // Connection mode: stdio, grpc
-	Workspace   string `json:"workspace,omitempty"`    // Workspace path for CLI-based providers
+	AuthMethod  string `json:"auth_method,omitempty"  yaml:"auth_method,omitempty"  toml:"auth_method,omitempty"`  // Authentication method: oauth, token
+	ConnectMode string `json:"connect_mode,omitempty" yaml:"connect_mode,omitempty" toml:"connect_mode,omitempty"` // Connection mode: stdio, grpc
+	Workspace   string `json:"workspace,omitempty"    yaml:"workspace,omitempty"    toml:"workspace,omitempty"`    // Workspace path for CLI-based providers
 
 	// Optional optimizations
-	RPM            int    `json:"rpm,omitempty"`              // Requests per minute limit
-	MaxTokensField string `json:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
-	RequestTimeout int    `json:"request_timeout,omitempty"`
+	RPM            int    `json:"rpm,omitempty"              yaml:"rpm,omitempty"              toml:"rpm,omitempty"`              // Requests per minute limit
+	MaxTokensField string `json:"max_tokens_field,omitempty" yaml:"max_tokens_field,omitempty" toml:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
+	RequestTimeout int    `json:"request_timeout,omitempty"  yaml:"request_timeout,omitempty"  toml:"request_timeout,omitempty"`
 }
 
 // Validate checks if the ModelConfig has all required fields.
@@ -423,113 +568,151 @@ func (c *ModelConfig) Validate() error {
 }
 
 type GatewayConfig struct {
-	Host string `json:"host" env:"PICOCLAW_GATEWAY_HOST"`
-	Port int    `json:"port" env:"PICOCLAW_GATEWAY_PORT"`
+	Host string `json:"host" yaml:"host" toml:"host" env:"PICOCLAW_GATEWAY_HOST"`
+	Port int    `json:"port" yaml:"port" toml:"port" env:"PICOCLAW_GATEWAY_PORT" schema:"minimum=1;maximum=65535"`
+}
+
+// BusServerConfig configures the message bus. When UnixSocketPath is set, the
+// gateway serves the bus on that socket to an external process, such as an
+// LLM running outside the gateway, instead of running the built-in agent.
+// DeadLetterFile, when set, collects inbound messages the bus failed to
+// deliver, as JSON lines. Setting NATSURL replaces the in-process bus with a
+// NATS JetStream stream (NATSStream, default "PICOCLAW") shared by every
+// process pointed at it; the socket and dead-letter options then do not apply.
+type BusServerConfig struct {
+	UnixSocketPath string `json:"unix_socket_path" yaml:"unix_socket_path" toml:"unix_socket_path" env:"PICOCLAW_BUS_UNIX_SOCKET_PATH"`
+	DeadLetterFile string `json:"dead_letter_file" yaml:"dead_letter_file" toml:"dead_letter_file" env:"PICOCLAW_BUS_DEAD_LETTER_FILE"`
+	NATSURL        string `json:"nats_url"         yaml:"nats_url"         toml:"nats_url"         env:"PICOCLAW_BUS_NATS_URL"`
+	NATSStream     string `json:"nats_stream"      yaml:"nats_stream"      toml:"nats_stream"      env:"PICOCLAW_BUS_NATS_STREAM"`
+}
+
+// RoutingConfig adjusts how inbound messages are handled by their kind.
+// SystemPromptOverrides maps a Metadata["event"] value, such as "superchat",
+// to the system prompt for messages of that event.
+type RoutingConfig struct {
+	SystemPromptOverrides map[string]string `json:"system_prompt_overrides,omitempty" yaml:"system_prompt_overrides,omitempty" toml:"system_prompt_overrides,omitempty"`
+}
+
+// PluginsConfig points at a directory of Go plugins (.so files) that add
+// inbound message filters and formatters to every channel. Loading needs a
+// binary built with cgo; the CGO_ENABLED=0 release builds ignore Dir.
+type PluginsConfig struct {
+	Dir string `json:"dir" yaml:"dir" toml:"dir" env:"PICOCLAW_PLUGINS_DIR"`
 }
 
 type BraveConfig struct {
-	Enabled    bool   `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_BRAVE_ENABLED"`
-	APIKey     string `json:"api_key"     env:"PICOCLAW_TOOLS_WEB_BRAVE_API_KEY"`
-	MaxResults int    `json:"max_results" env:"PICOCLAW_TOOLS_WEB_BRAVE_MAX_RESULTS"`
+	Enabled    bool   `json:"enabled"     yaml:"enabled"     toml:"enabled"     env:"PICOCLAW_TOOLS_WEB_BRAVE_ENABLED"`
+	APIKey     string `json:"api_key"     yaml:"api_key"     toml:"api_key"     env:"PICOCLAW_TOOLS_WEB_BRAVE_API_KEY"     config:"secret"`
+	MaxResults int    `json:"max_results" yaml:"max_results" toml:"max_results" env:"PICOCLAW_TOOLS_WEB_BRAVE_MAX_RESULTS"`
 }
 
 type TavilyConfig struct {
-	Enabled    bool   `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_TAVILY_ENABLED"`
-	APIKey     string `json:"api_key"     env:"PICOCLAW_TOOLS_WEB_TAVILY_API_KEY"`
-	BaseURL    string `json:"base_url"    env:"PICOCLAW_TOOLS_WEB_TAVILY_BASE_URL"`
-	MaxResults int    `json:"max_results" env:"PICOCLAW_TOOLS_WEB_TAVILY_MAX_RESULTS"`
+	Enabled    bool   `json:"enabled"     yaml:"enabled"     toml:"enabled"     env:"PICOCLAW_TOOLS_WEB_TAVILY_ENABLED"`
+	APIKey     string `json:"api_key"     yaml:"api_key"     toml:"api_key"     env:"PICOCLAW_TOOLS_WEB_TAVILY_API_KEY"     config:"secret"`
+	BaseURL    string `json:"base_url"    yaml:"base_url"    toml:"base_url"    env:"PICOCLAW_TOOLS_WEB_TAVILY_BASE_URL"`
+	MaxResults int    `json:"max_results" yaml:"max_results" toml:"max_results" env:"PICOCLAW_TOOLS_WEB_TAVILY_MAX_RESULTS"`
 }
 
 type DuckDuckGoConfig struct {
-	Enabled    bool `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_ENABLED"`
-	MaxResults int  `json:"max_results" env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_MAX_RESULTS"`
+	Enabled    bool `json:"enabled"     yaml:"enabled"     toml:"enabled"     env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_ENABLED"`
+	MaxResults int  `json:"max_results" yaml:"max_results" toml:"max_results" env:"PICOCLAW_TOOLS_WEB_DUCKDUCKGO_MAX_RESULTS"`
 }
 
 type PerplexityConfig struct {
-	Enabled    bool   `json:"enabled"     env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_ENABLED"`
-	APIKey     string `json:"api_key"     env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_API_KEY"`
-	MaxResults int    `json:"max_results" env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_MAX_RESULTS"`
+	Enabled    bool   `json:"enabled"     yaml:"enabled"     toml:"enabled"     env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_ENABLED"`
+	APIKey     string `json:"api_key"     yaml:"api_key"     toml:"api_key"     env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_API_KEY"     config:"secret"`
+	MaxResults int    `json:"max_results" yaml:"max_results" toml:"max_results" env:"PICOCLAW_TOOLS_WEB_PERPLEXITY_MAX_RESULTS"`
 }
 
 type WebToolsConfig struct {
-	Brave      BraveConfig      `json:"brave"`
-	Tavily     TavilyConfig     `json:"tavily"`
-	DuckDuckGo DuckDuckGoConfig `json:"duckduckgo"`
-	Perplexity PerplexityConfig `json:"perplexity"`
+	Brave      BraveConfig      `json:"brave"      yaml:"brave"      toml:"brave"`
+	Tavily     TavilyConfig     `json:"tavily"     yaml:"tavily"     toml:"tavily"`
+	DuckDuckGo DuckDuckGoConfig `json:"duckduckgo" yaml:"duckduckgo" toml:"duckduckgo"`
+	Perplexity PerplexityConfig `json:"perplexity" yaml:"perplexity" toml:"perplexity"`
 	// Proxy is an optional proxy URL for web tools (http/https/socks5/socks5h).
 	// For authenticated proxies, prefer HTTP_PROXY/HTTPS_PROXY env vars instead of embedding credentials in config.
-	Proxy string `json:"proxy,omitempty" env:"PICOCLAW_TOOLS_WEB_PROXY"`
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty" toml:"proxy,omitempty" env:"PICOCLAW_TOOLS_WEB_PROXY"`
 }
 
 type CronToolsConfig struct {
-	ExecTimeoutMinutes int `json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	ExecTimeoutMinutes FlexibleIntOrBool `json:"exec_timeout_minutes" yaml:"exec_timeout_minutes" toml:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
 }
 
 type ExecConfig struct {
-	EnableDenyPatterns bool     `json:"enable_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_ENABLE_DENY_PATTERNS"`
-	CustomDenyPatterns []string `json:"custom_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
+	EnableDenyPatterns bool     `json:"enable_deny_patterns" yaml:"enable_deny_patterns" toml:"enable_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_ENABLE_DENY_PATTERNS"`
+	CustomDenyPatterns []string `json:"custom_deny_patterns" yaml:"custom_deny_patterns" toml:"custom_deny_patterns" env:"PICOCLAW_TOOLS_EXEC_CUSTOM_DENY_PATTERNS"`
 }
 
 type ToolsConfig struct {
-	Web    WebToolsConfig    `json:"web"`
-	Cron   CronToolsConfig   `json:"cron"`
-	Exec   ExecConfig        `json:"exec"`
-	Skills SkillsToolsConfig `json:"skills"`
+	Web    WebToolsConfig    `json:"web"    yaml:"web"    toml:"web"`
+	Cron   CronToolsConfig   `json:"cron"   yaml:"cron"   toml:"cron"`
+	Exec   ExecConfig        `json:"exec"   yaml:"exec"   toml:"exec"`
+	Skills SkillsToolsConfig `json:"skills" yaml:"skills" toml:"skills"`
 }
 
 type SkillsToolsConfig struct {
-	Registries            SkillsRegistriesConfig `json:"registries"`
-	MaxConcurrentSearches int                    `json:"max_concurrent_searches" env:"PICOCLAW_SKILLS_MAX_CONCURRENT_SEARCHES"`
-	SearchCache           SearchCacheConfig      `json:"search_cache"`
+	Registries            SkillsRegistriesConfig `json:"registries"              yaml:"registries"              toml:"registries"`
+	MaxConcurrentSearches int                    `json:"max_concurrent_searches" yaml:"max_concurrent_searches" toml:"max_concurrent_searches" env:"PICOCLAW_SKILLS_MAX_CONCURRENT_SEARCHES"`
+	SearchCache           SearchCacheConfig      `json:"search_cache"            yaml:"search_cache"            toml:"search_cache"`
 }
 
 type SearchCacheConfig struct {
-	MaxSize    int `json:"max_size"    env:"PICOCLAW_SKILLS_SEARCH_CACHE_MAX_SIZE"`
-	TTLSeconds int `json:"ttl_seconds" env:"PICOCLAW_SKILLS_SEARCH_CACHE_TTL_SECONDS"`
+	MaxSize    int `json:"max_size"    yaml:"max_size"    toml:"max_size"    env:"PICOCLAW_SKILLS_SEARCH_CACHE_MAX_SIZE"`
+	TTLSeconds int `json:"ttl_seconds" yaml:"ttl_seconds" toml:"ttl_seconds" env:"PICOCLAW_SKILLS_SEARCH_CACHE_TTL_SECONDS"`
 }
 
 type SkillsRegistriesConfig struct {
-	ClawHub ClawHubRegistryConfig `json:"clawhub"`
+	ClawHub ClawHubRegistryConfig `json:"clawhub" yaml:"clawhub" toml:"clawhub"`
 }
 
 type ClawHubRegistryConfig struct {
-	Enabled         bool   `json:"enabled"           env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_ENABLED"`
-	BaseURL         string `json:"base_url"          env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_BASE_URL"`
-	AuthToken       string `json:"auth_token"        env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_AUTH_TOKEN"`
-	SearchPath      string `json:"search_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SEARCH_PATH"`
-	SkillsPath      string `json:"skills_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SKILLS_PATH"`
-	DownloadPath    string `json:"download_path"     env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_DOWNLOAD_PATH"`
-	Timeout         int    `json:"timeout"           env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_TIMEOUT"`
-	MaxZipSize      int    `json:"max_zip_size"      env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_ZIP_SIZE"`
-	MaxResponseSize int    `json:"max_response_size" env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_RESPONSE_SIZE"`
-}
-
+	Enabled         bool   `json:"enabled"           yaml:"enabled"           toml:"enabled"           env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_ENABLED"`
+	BaseURL         string `json:"base_url"          yaml:"base_url"          toml:"base_url"          env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_BASE_URL"`
+	AuthToken       string `json:"auth_token"        yaml:"auth_token"        toml:"auth_token"        env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_AUTH_TOKEN"        config:"secret"`
+	SearchPath      string `json:"search_path"       yaml:"search_path"       toml:"search_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SEARCH_PATH"`
+	SkillsPath      string `json:"skills_path"       yaml:"skills_path"       toml:"skills_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SKILLS_PATH"`
+	DownloadPath    string `json:"download_path"     yaml:"download_path"     toml:"download_path"     env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_DOWNLOAD_PATH"`
+	Timeout         int    `json:"timeout"           yaml:"timeout"           toml:"timeout"           env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_TIMEOUT"`
+	MaxZipSize      int    `json:"max_zip_size"      yaml:"max_zip_size"      toml:"max_zip_size"      env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_ZIP_SIZE"`
+	MaxResponseSize int    `json:"max_response_size" yaml:"max_response_size" toml:"max_response_size" env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_RESPONSE_SIZE"`
+}
+
+// LoadConfig reads the config file at path, choosing JSON, YAML or TOML
+// by its extension. A missing file yields the default config. Files older
+// than CurrentConfigVersion are migrated before they are decoded.
 func LoadConfig(path string) (*Config, error) {
 	cfg := DefaultConfig()
+	format := formatForPath(path)
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
+			cfg.Version = CurrentConfigVersion
 			return cfg, nil
 		}
 		return nil, err
 	}
 
-	// Pre-scan the JSON to check how many model_list entries the user provided.
+	data, format, err = migrateConfigData(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pre-scan the file to check how many model_list entries the user provided.
 	// Go's JSON decoder reuses existing slice backing-array elements rather than
 	// zero-initializing them, so fields absent from the user's JSON (e.g. api_base)
 	// would silently inherit values from the DefaultConfig template at the same
 	// index position. We only reset cfg.ModelList when the user actually provides
 	// entries; when count is 0 we keep DefaultConfig's built-in list as fallback.
 	var tmp Config
-	if err := json.Unmarshal(data, &tmp); err != nil {
+	if err := unmarshalConfig(format, data, &tmp); err != nil {
 		return nil, err
 	}
 	if len(tmp.ModelList) > 0 {
 		cfg.ModelList = nil
 	}
 
-	if err := json.Unmarshal(data, cfg); err != nil {
+	if err := unmarshalConfig(format, data, cfg); err != nil {
 		return nil, err
 	}
 
@@ -550,8 +733,13 @@ func LoadConfig(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// SaveConfig writes cfg to path in the format implied by its extension.
+// A config without a version is written as CurrentConfigVersion.
 func SaveConfig(path string, cfg *Config) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	if cfg.Version == 0 {
+		cfg.Version = CurrentConfigVersion
+	}
+	data, err := marshalConfig(formatForPath(path), cfg)
 	if err != nil {
 		return err
 	}
@@ -5,7 +5,9 @@
 
 package config
 
-// DefaultConfig returns the default configuration for PicoClaw.
+// DefaultConfig returns the default configuration for PicoClaw. Version is
+// left zero so that LoadConfig can tell files without a version field apart;
+// SaveConfig stamps CurrentConfigVersion on write.
 func DefaultConfig() *Config {
 	return &Config{
 		Agents: AgentsConfig{
@@ -28,11 +30,13 @@ func DefaultConfig() *Config {
 				Enabled:   false,
 				BridgeURL: "ws://localhost:3001",
 				AllowFrom: FlexibleStringSlice{},
+				BlockFrom: FlexibleStringSlice{},
 			},
 			Telegram: TelegramConfig{
 				Enabled:   false,
 				Token:     "",
 				AllowFrom: FlexibleStringSlice{},
+				BlockFrom: FlexibleStringSlice{},
 			},
 			Feishu: FeishuConfig{
 				Enabled:           false,
@@ -41,11 +45,13 @@ func DefaultConfig() *Config {
 				EncryptKey:        "",
 				VerificationToken: "",
 				AllowFrom:         FlexibleStringSlice{},
+				BlockFrom:         FlexibleStringSlice{},
 			},
 			Discord: DiscordConfig{
 				Enabled:     false,
 				Token:       "",
 				AllowFrom:   FlexibleStringSlice{},
+				BlockFrom:   FlexibleStringSlice{},
 				MentionOnly: false,
 			},
 			MaixCam: MaixCamConfig{
@@ -53,24 +59,31 @@ func DefaultConfig() *Config {
 				Host:      "0.0.0.0",
 				Port:      18790,
 				AllowFrom: FlexibleStringSlice{},
+				BlockFrom: FlexibleStringSlice{},
 			},
 			QQ: QQConfig{
 				Enabled:   false,
 				AppID:     "",
 				AppSecret: "",
 				AllowFrom: FlexibleStringSlice{},
+				BlockFrom: FlexibleStringSlice{},
 			},
 			DingTalk: DingTalkConfig{
 				Enabled:      false,
 				ClientID:     "",
 				ClientSecret: "",
 				AllowFrom:    FlexibleStringSlice{},
+				BlockFrom:    FlexibleStringSlice{},
 			},
 			Slack: SlackConfig{
-				Enabled:   false,
-				BotToken:  "",
-				AppToken:  "",
-				AllowFrom: FlexibleStringSlice{},
+				Enabled:       false,
+				BotToken:      "",
+				AppToken:      "",
+				SigningSecret: "",
+				ListenPath:    "/webhook/slack",
+				ListenPort:    18794,
+				AllowFrom:     FlexibleStringSlice{},
+				BlockFrom:     FlexibleStringSlice{},
 			},
 			LINE: LINEConfig{
 				Enabled:            false,
@@ -80,6 +93,7 @@ func DefaultConfig() *Config {
 				WebhookPort:        18791,
 				WebhookPath:        "/webhook/line",
 				AllowFrom:          FlexibleStringSlice{},
+				BlockFrom:          FlexibleStringSlice{},
 			},
 			OneBot: OneBotConfig{
 				Enabled:            false,
@@ -88,6 +102,7 @@ func DefaultConfig() *Config {
 				ReconnectInterval:  5,
 				GroupTriggerPrefix: []string{},
 				AllowFrom:          FlexibleStringSlice{},
+				BlockFrom:          FlexibleStringSlice{},
 			},
 			WeCom: WeComConfig{
 				Enabled:        false,
@@ -98,6 +113,7 @@ func DefaultConfig() *Config {
 				WebhookPort:    18793,
 				WebhookPath:    "/webhook/wecom",
 				AllowFrom:      FlexibleStringSlice{},
+				BlockFrom:      FlexibleStringSlice{},
 				ReplyTimeout:   5,
 			},
 			WeComApp: WeComAppConfig{
@@ -111,8 +127,50 @@ func DefaultConfig() *Config {
 				WebhookPort:    18792,
 				WebhookPath:    "/webhook/wecom-app",
 				AllowFrom:      FlexibleStringSlice{},
+				BlockFrom:      FlexibleStringSlice{},
 				ReplyTimeout:   5,
 			},
+			TwitchEventSub: TwitchEventSubConfig{
+				Enabled:           false,
+				ClientID:          "",
+				UserAccessToken:   "",
+				BroadcasterUserID: "",
+				AllowFrom:         FlexibleStringSlice{},
+				BlockFrom:         FlexibleStringSlice{},
+			},
+			Twicas: TwicasConfig{
+				Enabled:             false,
+				MovieID:             "",
+				AccessToken:         "",
+				PollIntervalSeconds: 5,
+				MessageFormat:       "{message}",
+				NGWords:             FlexibleStringSlice{},
+				AllowFrom:           FlexibleStringSlice{},
+				BlockFrom:           FlexibleStringSlice{},
+			},
+			NicoNicoTimeshift: TimeshiftConfig{
+				Enabled:       false,
+				ContentID:     "",
+				UserSession:   "",
+				PlaybackSpeed: 1,
+				AllowFrom:     FlexibleStringSlice{},
+				BlockFrom:     FlexibleStringSlice{},
+			},
+			LineLive: LineLiveConfig{
+				Enabled:       false,
+				BroadcastID:   "",
+				AccessToken:   "",
+				MessageFormat: "{message}",
+				NGWords:       FlexibleStringSlice{},
+				AllowFrom:     FlexibleStringSlice{},
+				BlockFrom:     FlexibleStringSlice{},
+			},
+			Notion: NotionConfig{
+				Enabled:          false,
+				IntegrationToken: "",
+				DatabaseID:       "",
+				TitleProperty:    "Name",
+			},
 		},
 		Providers: ProvidersConfig{
 			OpenAI: OpenAIProviderConfig{WebSearch: true},
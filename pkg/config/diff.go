@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// redacted stands in for the value of a field tagged config:"secret".
+const redacted = "[REDACTED]"
+
+// ConfigChange is one setting that differs between two configs. Path is the
+// dotted JSON path of the setting, e.g. "channels.twicas.movie_id", with
+// list entries written as "model_list[2]".
+type ConfigChange struct {
+	Path     string
+	OldValue any
+	NewValue any
+}
+
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.OldValue, c.NewValue)
+}
+
+// Diff returns the settings that differ between a and b, for logging what a
+// reload changed. It compares leaf values field by field; a value present on
+// only one side, such as an added list entry, is reported against nil. NG word
+// lists are compared ignoring order and duplicates, and the values of secret
+// fields are replaced by "[REDACTED]".
+func Diff(a, b *Config) []ConfigChange {
+	var changes []ConfigChange
+	diffValues(&changes, "", reflect.ValueOf(a), reflect.ValueOf(b), false)
+	return changes
+}
+
+// diffValues compares a and b, either of which may be invalid when the value
+// exists on one side only.
+func diffValues(changes *[]ConfigChange, path string, a, b reflect.Value, secret bool) {
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() && !b.IsValid() {
+		return
+	}
+	t := b.Type()
+	if a.IsValid() {
+		t = a.Type()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			fieldPath := path
+			if !f.Anonymous || name != "" {
+				if name == "" {
+					name = f.Name
+				}
+				fieldPath = joinPath(path, name)
+			}
+			fieldSecret := secret || f.Tag.Get("config") == "secret"
+			if f.Name == "NGWords" {
+				diffSet(changes, fieldPath, field(a, i), field(b, i))
+				continue
+			}
+			diffValues(changes, fieldPath, field(a, i), field(b, i), fieldSecret)
+		}
+	case reflect.Slice, reflect.Array:
+		n := max(length(a), length(b))
+		for i := range n {
+			diffValues(changes, fmt.Sprintf("%s[%d]", path, i), index(a, i), index(b, i), secret)
+		}
+	case reflect.Map:
+		for _, key := range mapKeys(a, b) {
+			diffValues(changes, joinPath(path, fmt.Sprint(key.Interface())), mapIndex(a, key), mapIndex(b, key), secret)
+		}
+	default:
+		oldValue, newValue := leaf(a), leaf(b)
+		if reflect.DeepEqual(oldValue, newValue) {
+			return
+		}
+		if secret {
+			oldValue, newValue = redacted, redacted
+		}
+		*changes = append(*changes, ConfigChange{Path: path, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// diffSet reports a change when the string lists a and b hold different sets
+// of words.
+func diffSet(changes *[]ConfigChange, path string, a, b reflect.Value) {
+	oldValue, newValue := stringSet(a), stringSet(b)
+	if slices.Equal(oldValue, newValue) {
+		return
+	}
+	*changes = append(*changes, ConfigChange{Path: path, OldValue: oldValue, NewValue: newValue})
+}
+
+// stringSet returns the distinct entries of a string list, sorted.
+func stringSet(v reflect.Value) []string {
+	set := make([]string, 0, length(v))
+	for i := range length(v) {
+		set = append(set, v.Index(i).String())
+	}
+	slices.Sort(set)
+	return slices.Compact(set)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	return v
+}
+
+func field(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.Field(i)
+}
+
+func length(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+	return v.Len()
+}
+
+func index(v reflect.Value, i int) reflect.Value {
+	if i >= length(v) {
+		return reflect.Value{}
+	}
+	return v.Index(i)
+}
+
+func mapIndex(v reflect.Value, key reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.MapIndex(key)
+}
+
+// mapKeys returns the keys of a and b together, sorted for a stable order.
+func mapKeys(a, b reflect.Value) []reflect.Value {
+	seen := make(map[string]reflect.Value)
+	for _, m := range []reflect.Value{a, b} {
+		if m.IsValid() {
+			for _, key := range m.MapKeys() {
+				seen[fmt.Sprint(key.Interface())] = key
+			}
+		}
+	}
+	keys := make([]reflect.Value, 0, len(seen))
+	for _, name := range slices.Sorted(maps.Keys(seen)) {
+		keys = append(keys, seen[name])
+	}
+	return keys
+}
+
+func leaf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiffReportsChangedFields(t *testing.T) {
+	a := DefaultConfig()
+	a.Channels.Twicas.NGWords = FlexibleStringSlice{"spam", "ad"}
+	b := DefaultConfig()
+	b.Channels.Twicas.NGWords = FlexibleStringSlice{"ad", "spam", "ad"} // same set
+
+	b.Agents.Defaults.MaxTokens = a.Agents.Defaults.MaxTokens + 1
+	b.Channels.Twicas.MovieID = "m2"
+	b.Channels.Telegram.Token = "new-token"
+
+	want := []ConfigChange{
+		{Path: "agents.defaults.max_tokens", OldValue: a.Agents.Defaults.MaxTokens, NewValue: b.Agents.Defaults.MaxTokens},
+		{Path: "channels.telegram.token", OldValue: "[REDACTED]", NewValue: "[REDACTED]"},
+		{Path: "channels.twicas.movie_id", OldValue: "", NewValue: "m2"},
+	}
+	if got := Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestDiffNGWordsAndListEntries(t *testing.T) {
+	a := DefaultConfig()
+	b := DefaultConfig()
+	b.Channels.LineLive.NGWords = FlexibleStringSlice{"spam"}
+	b.ModelList = append(b.ModelList, ModelConfig{ModelName: "extra", APIKey: "sk-secret"})
+
+	changes := Diff(a, b)
+	byPath := make(map[string]ConfigChange, len(changes))
+	for _, c := range changes {
+		byPath[c.Path] = c
+	}
+
+	ng := byPath["channels.linelive.ng_words"]
+	if !reflect.DeepEqual(ng.NewValue, []string{"spam"}) {
+		t.Errorf("ng_words change = %v", ng)
+	}
+	added := len(a.ModelList)
+	if c := byPath[fmt.Sprintf("model_list[%d].model_name", added)]; c.OldValue != nil || c.NewValue != "extra" {
+		t.Errorf("model_name change = %v", c)
+	}
+	if c := byPath[fmt.Sprintf("model_list[%d].api_key", added)]; c.NewValue != "[REDACTED]" {
+		t.Errorf("api_key change = %v, want redacted", c)
+	}
+	if got := Diff(a, DefaultConfig()); len(got) != 0 {
+		t.Errorf("Diff of equal configs = %v", got)
+	}
+}
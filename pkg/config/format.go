@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFormat is the on-disk encoding of a config file.
+type configFormat string
+
+const (
+	formatJSON configFormat = "json"
+	formatYAML configFormat = "yaml"
+	formatTOML configFormat = "toml"
+)
+
+// formatForPath picks the config format from the file extension:
+// .yaml/.yml → YAML, .toml → TOML, anything else → JSON.
+func formatForPath(path string) configFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	default:
+		return formatJSON
+	}
+}
+
+func unmarshalConfig(format configFormat, data []byte, v any) error {
+	switch format {
+	case formatYAML:
+		return yaml.Unmarshal(data, v)
+	case formatTOML:
+		return toml.Unmarshal(data, v)
+	default:
+		return json.Unmarshal(data, v)
+	}
+}
+
+func marshalConfig(format configFormat, cfg *Config) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(cfg); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return json.MarshalIndent(cfg, "", "  ")
+	}
+}
+
+// UnmarshalYAML accepts numeric entries as well as strings, and a single
+// scalar in place of a list, matching UnmarshalJSON.
+func (f *FlexibleStringSlice) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.ShortTag() == "!!null" {
+			return nil
+		}
+		*f = FlexibleStringSlice{value.Value}
+		return nil
+	}
+	if value.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: expected a list, got %s", value.Line, value.ShortTag())
+	}
+	result := make([]string, 0, len(value.Content))
+	for _, item := range value.Content {
+		if item.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: expected a scalar list entry", item.Line)
+		}
+		result = append(result, item.Value)
+	}
+	*f = result
+	return nil
+}
+
+// UnmarshalTOML accepts numeric entries as well as strings, and a single
+// value in place of an array, matching UnmarshalJSON.
+func (f *FlexibleStringSlice) UnmarshalTOML(data any) error {
+	var raw []any
+	switch val := data.(type) {
+	case []any:
+		raw = val
+	case string, int64, float64:
+		raw = []any{val}
+	default:
+		return fmt.Errorf("expected an array, got %T", data)
+	}
+	result := make([]string, 0, len(raw))
+	for _, v := range raw {
+		switch val := v.(type) {
+		case string:
+			result = append(result, val)
+		case int64:
+			result = append(result, strconv.FormatInt(val, 10))
+		case float64:
+			result = append(result, fmt.Sprintf("%.0f", val))
+		default:
+			result = append(result, fmt.Sprintf("%v", val))
+		}
+	}
+	*f = result
+	return nil
+}
+
+// UnmarshalYAML accepts 20, "20" and booleans, matching UnmarshalJSON.
+func (f *FlexibleIntOrBool) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected an integer, got %s", value.Line, value.ShortTag())
+	}
+	if value.ShortTag() == "!!null" {
+		return nil
+	}
+	var v any = value.Value
+	if value.ShortTag() == "!!bool" {
+		var b bool
+		if err := value.Decode(&b); err != nil {
+			return err
+		}
+		v = b
+	}
+	n, err := flexibleInt(v)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*f = FlexibleIntOrBool(n)
+	return nil
+}
+
+// UnmarshalTOML accepts 20, "20" and booleans, matching UnmarshalJSON.
+func (f *FlexibleIntOrBool) UnmarshalTOML(data any) error {
+	n, err := flexibleInt(data)
+	if err != nil {
+		return err
+	}
+	*f = FlexibleIntOrBool(n)
+	return nil
+}
+
+// UnmarshalYAML accepts either a bare model name or a {primary, fallbacks} mapping.
+func (m *AgentModelConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		m.Primary = value.Value
+		m.Fallbacks = nil
+		return nil
+	}
+	type raw struct {
+		Primary   string   `yaml:"primary"`
+		Fallbacks []string `yaml:"fallbacks"`
+	}
+	var r raw
+	if err := value.Decode(&r); err != nil {
+		return err
+	}
+	m.Primary = r.Primary
+	m.Fallbacks = r.Fallbacks
+	return nil
+}
+
+func (m AgentModelConfig) MarshalYAML() (any, error) {
+	if len(m.Fallbacks) == 0 && m.Primary != "" {
+		return m.Primary, nil
+	}
+	type raw struct {
+		Primary   string   `yaml:"primary,omitempty"`
+		Fallbacks []string `yaml:"fallbacks,omitempty"`
+	}
+	return raw{Primary: m.Primary, Fallbacks: m.Fallbacks}, nil
+}
+
+// UnmarshalTOML accepts either a bare model name or a {primary, fallbacks} table.
+func (m *AgentModelConfig) UnmarshalTOML(data any) error {
+	switch val := data.(type) {
+	case string:
+		m.Primary = val
+		m.Fallbacks = nil
+	case map[string]any:
+		m.Primary, _ = val["primary"].(string)
+		m.Fallbacks = nil
+		if list, ok := val["fallbacks"].([]any); ok {
+			for _, item := range list {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("model fallbacks: expected string, got %T", item)
+				}
+				m.Fallbacks = append(m.Fallbacks, s)
+			}
+		}
+	default:
+		return fmt.Errorf("model: expected string or table, got %T", data)
+	}
+	return nil
+}
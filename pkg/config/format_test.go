@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/caarlos0/env/v11"
+)
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]configFormat{
+		"config.json":         formatJSON,
+		"config.yaml":         formatYAML,
+		"config.YML":          formatYAML,
+		"config.toml":         formatTOML,
+		"config":              formatJSON,
+		"/etc/picoclaw/a.cfg": formatJSON,
+	}
+	for path, want := range tests {
+		if got := formatForPath(path); got != want {
+			t.Errorf("formatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func roundTripConfig() *Config {
+	cfg := DefaultConfig()
+	temp := 0.3
+	cfg.Agents.Defaults.Temperature = &temp
+	cfg.Agents.List = []AgentConfig{
+		{ID: "main", Default: true, Model: &AgentModelConfig{Primary: "gpt-4"}},
+		{
+			ID:     "research",
+			Model:  &AgentModelConfig{Primary: "claude-opus", Fallbacks: []string{"haiku"}},
+			Skills: []string{"web"},
+		},
+	}
+	cfg.Bindings = []AgentBinding{{AgentID: "research", Match: BindingMatch{Channel: "slack", TeamID: "T1"}}}
+	cfg.Session.IdentityLinks = map[string][]string{"alice": {"telegram:1", "slack:U1"}}
+	cfg.Channels.Telegram.Enabled = true
+	cfg.Channels.Telegram.Token = "123:abc"
+	cfg.Channels.Telegram.AllowFrom = FlexibleStringSlice{"123", "alice"}
+	cfg.Channels.Slack.BlockFrom = FlexibleStringSlice{"U_SPAM"}
+	cfg.Tools.Exec.CustomDenyPatterns = []string{`\bshutdown\b`}
+	cfg.Gateway.Port = 19000
+	return cfg
+}
+
+func TestConfigRoundTripAllFormats(t *testing.T) {
+	want := roundTripConfig()
+	dir := t.TempDir()
+
+	for _, name := range []string{"config.json", "config.yaml", "config.toml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := SaveConfig(path, want); err != nil {
+				t.Fatalf("SaveConfig: %v", err)
+			}
+			got, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip through %s changed the config\ngot:  %+v\nwant: %+v", name, got, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_YAMLAndTOMLFlexibleValues(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+agents:
+  defaults:
+    model_name: gpt-4
+  list:
+    - id: main
+      model: gpt-4
+    - id: backup
+      model:
+        primary: claude-opus
+        fallbacks: [haiku]
+channels:
+  telegram:
+    allow_from: [123, "alice"]
+`,
+		"config.toml": `
+[agents.defaults]
+model_name = "gpt-4"
+
+[[agents.list]]
+id = "main"
+model = "gpt-4"
+
+[[agents.list]]
+id = "backup"
+model = { primary = "claude-opus", fallbacks = ["haiku"] }
+
+[channels.telegram]
+allow_from = [123, "alice"]
+`,
+	}
+
+	dir := t.TempDir()
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if got := cfg.Channels.Telegram.AllowFrom; !reflect.DeepEqual(got, FlexibleStringSlice{"123", "alice"}) {
+				t.Errorf("allow_from = %v, want [123 alice]", got)
+			}
+			if len(cfg.Agents.List) != 2 {
+				t.Fatalf("agents.list len = %d, want 2", len(cfg.Agents.List))
+			}
+			if m := cfg.Agents.List[0].Model; m == nil || m.Primary != "gpt-4" || m.Fallbacks != nil {
+				t.Errorf("string model = %+v", m)
+			}
+			if m := cfg.Agents.List[1].Model; m == nil || m.Primary != "claude-opus" ||
+				!reflect.DeepEqual(m.Fallbacks, []string{"haiku"}) {
+				t.Errorf("table model = %+v", m)
+			}
+		})
+	}
+}
+
+func TestFlexibleScalarsAcrossFormats(t *testing.T) {
+	type sample struct {
+		AllowFrom FlexibleStringSlice `json:"allow_from" yaml:"allow_from" toml:"allow_from"`
+		Interval  FlexibleIntOrBool   `json:"interval"   yaml:"interval"   toml:"interval"`
+		Enabled   FlexibleIntOrBool   `json:"enabled"    yaml:"enabled"    toml:"enabled"`
+	}
+
+	tests := []struct {
+		name   string
+		format configFormat
+		data   string
+		want   sample
+	}{
+		{
+			"json list", formatJSON, `{"allow_from": ["UCfoo", 42], "interval": 20, "enabled": true}`,
+			sample{FlexibleStringSlice{"UCfoo", "42"}, 20, 1},
+		},
+		{
+			"json scalars", formatJSON, `{"allow_from": "UCfoo", "interval": "20", "enabled": "false"}`,
+			sample{FlexibleStringSlice{"UCfoo"}, 20, 0},
+		},
+		{
+			"json number scalar", formatJSON, `{"allow_from": 42, "interval": " 20 ", "enabled": 1}`,
+			sample{FlexibleStringSlice{"42"}, 20, 1},
+		},
+		{
+			"yaml flow list", formatYAML, "allow_from: [UCfoo, 42]\ninterval: 20\nenabled: true\n",
+			sample{FlexibleStringSlice{"UCfoo", "42"}, 20, 1},
+		},
+		{
+			"yaml block list", formatYAML, "allow_from:\n  - UCfoo\n  - 42\ninterval: \"20\"\nenabled: True\n",
+			sample{FlexibleStringSlice{"UCfoo", "42"}, 20, 1},
+		},
+		{
+			"yaml scalars", formatYAML, "allow_from: UCfoo\ninterval: '20'\nenabled: false\n",
+			sample{FlexibleStringSlice{"UCfoo"}, 20, 0},
+		},
+		{
+			"yaml null", formatYAML, "allow_from:\ninterval:\n",
+			sample{},
+		},
+		{
+			"toml array", formatTOML, "allow_from = [\"UCfoo\", 42]\ninterval = 20\nenabled = true\n",
+			sample{FlexibleStringSlice{"UCfoo", "42"}, 20, 1},
+		},
+		{
+			"toml scalars", formatTOML, "allow_from = \"UCfoo\"\ninterval = \"20\"\nenabled = false\n",
+			sample{FlexibleStringSlice{"UCfoo"}, 20, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got sample
+			if err := unmarshalConfig(tt.format, []byte(tt.data), &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexibleIntOrBoolRejectsGarbage(t *testing.T) {
+	inputs := map[configFormat]string{
+		formatJSON: `{"interval": "soon"}`,
+		formatYAML: "interval: soon\n",
+		formatTOML: "interval = 2.5\n",
+	}
+	for format, data := range inputs {
+		var v struct {
+			Interval FlexibleIntOrBool `json:"interval" yaml:"interval" toml:"interval"`
+		}
+		if err := unmarshalConfig(format, []byte(data), &v); err == nil {
+			t.Errorf("%s: expected an error for %q", format, data)
+		}
+	}
+}
+
+func TestFlexibleIntOrBoolFromEnv(t *testing.T) {
+	t.Setenv("PICOCLAW_HEARTBEAT_INTERVAL", "45")
+	cfg := DefaultConfig()
+	if err := env.Parse(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Heartbeat.Interval != 45 {
+		t.Errorf("heartbeat interval = %d, want 45", cfg.Heartbeat.Interval)
+	}
+}
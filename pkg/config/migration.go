@@ -6,10 +6,115 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"slices"
 	"strings"
 )
 
+// CurrentConfigVersion is the config schema version written by this build.
+// Files without a "version" field are treated as version 1.
+const CurrentConfigVersion = 2
+
+// configMigrations[i] upgrades a decoded config from version i+1 to i+2.
+var configMigrations = []func(root map[string]any) error{
+	migrateV1ToV2,
+}
+
+// MigrateConfig upgrades a JSON config from fromVersion to toVersion by
+// applying each single-version step in turn, so migrating 1→2 and then 2→3
+// gives the same result as migrating 1→3 directly. The output is indented
+// JSON with sorted keys, which keeps it stable across runs.
+func MigrateConfig(input []byte, fromVersion, toVersion int) ([]byte, error) {
+	if fromVersion < 1 || toVersion > len(configMigrations)+1 || fromVersion > toVersion {
+		return nil, fmt.Errorf("unsupported config migration from v%d to v%d", fromVersion, toVersion)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+	var root map[string]any
+	if err := dec.Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if root == nil {
+		root = map[string]any{}
+	}
+
+	for v := fromVersion; v < toVersion; v++ {
+		if err := configMigrations[v-1](root); err != nil {
+			return nil, fmt.Errorf("migrating config v%d to v%d: %w", v, v+1, err)
+		}
+		root["version"] = v + 1
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// migrateConfigData upgrades a config file's contents to
+// CurrentConfigVersion when its version field is older. MigrateConfig works
+// on JSON, so a migrated YAML or TOML file comes back as JSON along with
+// formatJSON; files that are already current are returned unchanged.
+func migrateConfigData(format configFormat, data []byte) ([]byte, configFormat, error) {
+	var header struct {
+		Version int `json:"version" yaml:"version" toml:"version"`
+	}
+	if err := unmarshalConfig(format, data, &header); err != nil {
+		return nil, format, err
+	}
+	fromVersion := max(header.Version, 1)
+	if fromVersion >= CurrentConfigVersion {
+		return data, format, nil
+	}
+
+	input := data
+	if format != formatJSON {
+		var root map[string]any
+		if err := unmarshalConfig(format, data, &root); err != nil {
+			return nil, format, err
+		}
+		var err error
+		if input, err = json.Marshal(root); err != nil {
+			return nil, format, err
+		}
+	}
+	migrated, err := MigrateConfig(input, fromVersion, CurrentConfigVersion)
+	if err != nil {
+		return nil, format, err
+	}
+	return migrated, formatJSON, nil
+}
+
+// migrateV1ToV2 moves the deprecated agents.defaults.model field to
+// model_name. An existing model_name wins and the old field is dropped.
+func migrateV1ToV2(root map[string]any) error {
+	agents, _ := root["agents"].(map[string]any)
+	defaults, _ := agents["defaults"].(map[string]any)
+	if defaults == nil {
+		return nil
+	}
+
+	model, ok := defaults["model"]
+	if !ok {
+		return nil
+	}
+	if _, ok := model.(string); !ok {
+		return fmt.Errorf("agents.defaults.model must be a string")
+	}
+	if name, _ := defaults["model_name"].(string); name == "" {
+		defaults["model_name"] = model
+	}
+	delete(defaults, "model")
+	return nil
+}
+
 // buildModelWithProtocol constructs a model string with protocol prefix.
 // If the model already contains a "/" (indicating it has a protocol prefix), it is returned as-is.
 // Otherwise, the protocol prefix is added.
@@ -6,6 +6,11 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -581,3 +586,158 @@ func TestConvertProvidersToModelList_LegacyModelWithProtocolPrefix(t *testing.T)
 		t.Errorf("Model = %q, want %q (should not duplicate prefix)", result[0].Model, "openrouter/auto")
 	}
 }
+
+func TestMigrateConfig_V1ToV2Fixtures(t *testing.T) {
+	for _, name := range []string{"legacy_model", "both_models"} {
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", "migrate", name+".v1.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("testdata", "migrate", name+".v2.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := MigrateConfig(input, 1, 2)
+			if err != nil {
+				t.Fatalf("MigrateConfig: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("migrated config mismatch\ngot:\n%s\nwant:\n%s", got, want)
+			}
+
+			var cfg Config
+			if err := json.Unmarshal(got, &cfg); err != nil {
+				t.Fatalf("migrated config does not load: %v", err)
+			}
+			if cfg.Version != 2 {
+				t.Errorf("Version = %d, want 2", cfg.Version)
+			}
+		})
+	}
+}
+
+func TestMigrateConfig_Composable(t *testing.T) {
+	orig := configMigrations
+	t.Cleanup(func() { configMigrations = orig })
+	configMigrations = append(slices.Clone(orig), func(root map[string]any) error {
+		root["v3_marker"] = true
+		return nil
+	})
+
+	input, err := os.ReadFile(filepath.Join("testdata", "migrate", "legacy_model.v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	direct, err := MigrateConfig(input, 1, 3)
+	if err != nil {
+		t.Fatalf("MigrateConfig 1->3: %v", err)
+	}
+	v2, err := MigrateConfig(input, 1, 2)
+	if err != nil {
+		t.Fatalf("MigrateConfig 1->2: %v", err)
+	}
+	stepped, err := MigrateConfig(v2, 2, 3)
+	if err != nil {
+		t.Fatalf("MigrateConfig 2->3: %v", err)
+	}
+	if !bytes.Equal(direct, stepped) {
+		t.Errorf("1->3 and 1->2->3 differ\ndirect:\n%s\nstepped:\n%s", direct, stepped)
+	}
+}
+
+func TestMigrateConfig_UnsupportedVersions(t *testing.T) {
+	for _, tc := range [][2]int{{0, 2}, {2, 1}, {1, CurrentConfigVersion + 1}} {
+		if _, err := MigrateConfig([]byte(`{}`), tc[0], tc[1]); err == nil {
+			t.Errorf("MigrateConfig(%d, %d) should fail", tc[0], tc[1])
+		}
+	}
+}
+
+func TestMigrateConfig_InvalidModel(t *testing.T) {
+	if _, err := MigrateConfig([]byte(`{"agents":{"defaults":{"model":42}}}`), 1, 2); err == nil {
+		t.Error("expected error for non-string agents.defaults.model")
+	}
+}
+
+func TestLoadConfig_MigratesV1File(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("testdata", "migrate", "legacy_model.v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, input, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Version != CurrentConfigVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, CurrentConfigVersion)
+	}
+	if cfg.Agents.Defaults.ModelName != "gpt-4o" {
+		t.Errorf("ModelName = %q, want %q", cfg.Agents.Defaults.ModelName, "gpt-4o")
+	}
+	if cfg.Agents.Defaults.Model != "" {
+		t.Errorf("Model = %q, want it moved to model_name", cfg.Agents.Defaults.Model)
+	}
+	if cfg.Agents.Defaults.MaxTokens != 8192 {
+		t.Errorf("MaxTokens = %d, want 8192", cfg.Agents.Defaults.MaxTokens)
+	}
+}
+
+func TestLoadConfig_MigratesV1YAMLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "agents:\n  defaults:\n    model: gpt-4o\n    max_tokens: 8192\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Version != CurrentConfigVersion || cfg.Agents.Defaults.ModelName != "gpt-4o" {
+		t.Errorf("Version = %d, ModelName = %q; want %d, %q",
+			cfg.Version, cfg.Agents.Defaults.ModelName, CurrentConfigVersion, "gpt-4o")
+	}
+}
+
+func TestLoadConfig_CurrentVersionNotMigrated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"version": 2, "agents": {"defaults": {"model": "gpt-4o"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Agents.Defaults.Model != "gpt-4o" || cfg.Agents.Defaults.ModelName != "" {
+		t.Errorf("Model = %q, ModelName = %q; a v2 file should load as written",
+			cfg.Agents.Defaults.Model, cfg.Agents.Defaults.ModelName)
+	}
+}
+
+func TestSaveConfig_StampsCurrentVersion(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Version != 0 {
+		t.Fatalf("DefaultConfig().Version = %d, want 0", cfg.Version)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.Version != CurrentConfigVersion {
+		t.Errorf("saved Version = %d, want %d", loaded.Version, CurrentConfigVersion)
+	}
+}
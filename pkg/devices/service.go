@@ -14,7 +14,7 @@ import (
 )
 
 type Service struct {
-	bus     *bus.MessageBus
+	bus     bus.Publisher
 	state   *state.Manager
 	sources []events.EventSource
 	enabled bool
@@ -43,7 +43,7 @@ func NewService(cfg Config, stateMgr *state.Manager) *Service {
 	return s
 }
 
-func (s *Service) SetBus(msgBus *bus.MessageBus) {
+func (s *Service) SetBus(msgBus bus.Publisher) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.bus = msgBus
@@ -127,7 +127,7 @@ func (s *Service) sendNotification(ev *events.DeviceEvent) {
 	}
 
 	msg := ev.FormatMessage()
-	msgBus.PublishOutbound(bus.OutboundMessage{
+	msgBus.PublishOutbound(s.ctx, bus.OutboundMessage{
 		Channel: platform,
 		ChatID:  userID,
 		Content: msg,
@@ -7,6 +7,7 @@
 package heartbeat
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,7 +36,7 @@ type HeartbeatHandler func(prompt, channel, chatID string) *tools.ToolResult
 // HeartbeatService manages periodic heartbeat checks
 type HeartbeatService struct {
 	workspace string
-	bus       *bus.MessageBus
+	bus       bus.Publisher
 	state     *state.Manager
 	handler   HeartbeatHandler
 	interval  time.Duration
@@ -64,7 +65,7 @@ func NewHeartbeatService(workspace string, intervalMinutes int, enabled bool) *H
 }
 
 // SetBus sets the message bus for delivering heartbeat results.
-func (hs *HeartbeatService) SetBus(msgBus *bus.MessageBus) {
+func (hs *HeartbeatService) SetBus(msgBus bus.Publisher) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 	hs.bus = msgBus
@@ -308,7 +309,7 @@ func (hs *HeartbeatService) sendResponse(response string) {
 		return
 	}
 
-	msgBus.PublishOutbound(bus.OutboundMessage{
+	msgBus.PublishOutbound(context.Background(), bus.OutboundMessage{
 		Channel: platform,
 		ChatID:  userID,
 		Content: response,
@@ -31,15 +31,10 @@ var (
 	}
 
 	currentLevel = INFO
-	logger       *Logger
-	once         sync.Once
+	logFile      *os.File
 	mu           sync.RWMutex
 )
 
-type Logger struct {
-	file *os.File
-}
-
 type LogEntry struct {
 	Level     string         `json:"level"`
 	Timestamp string         `json:"timestamp"`
@@ -49,12 +44,6 @@ type LogEntry struct {
 	Caller    string         `json:"caller,omitempty"`
 }
 
-func init() {
-	once.Do(func() {
-		logger = &Logger{}
-	})
-}
-
 func SetLevel(level LogLevel) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -76,11 +65,11 @@ func EnableFileLogging(filePath string) error {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
 
-	if logger.file != nil {
-		logger.file.Close()
+	if logFile != nil {
+		logFile.Close()
 	}
 
-	logger.file = file
+	logFile = file
 	log.Println("File logging enabled:", filePath)
 	return nil
 }
@@ -89,9 +78,9 @@ func DisableFileLogging() {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if logger.file != nil {
-		logger.file.Close()
-		logger.file = nil
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
 		log.Println("File logging disabled")
 	}
 }
@@ -116,10 +105,10 @@ func logMessage(level LogLevel, component string, message string, fields map[str
 		}
 	}
 
-	if logger.file != nil {
+	if logFile != nil {
 		jsonData, err := json.Marshal(entry)
 		if err == nil {
-			logger.file.Write(append(jsonData, '\n'))
+			logFile.Write(append(jsonData, '\n'))
 		}
 	}
 
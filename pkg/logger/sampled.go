@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"maps"
+	"sync"
+)
+
+// SampledLogger thins out a message that repeats while a condition persists,
+// such as a poll failing every few seconds during an outage. For each key it
+// logs the first few occurrences and then only one in every so many, until
+// Reset is called for the key once the condition clears. Sampled lines carry
+// an "occurrences" field with the count so far.
+type SampledLogger struct {
+	first int
+	every int
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewSampledLogger returns a SampledLogger that logs the first `first`
+// occurrences of a key and after that every `every`-th.
+func NewSampledLogger(first, every int) *SampledLogger {
+	return &SampledLogger{first: first, every: every}
+}
+
+// defaultSampler backs SampledWarnCF, SampledErrorCF and Logger.SampledWarnF.
+var defaultSampler = NewSampledLogger(3, 100)
+
+// WarnCF logs like the package WarnCF, subject to sampling by key.
+func (s *SampledLogger) WarnCF(key, component, message string, fields map[string]any) {
+	if fields, ok := s.sample(key, fields); ok {
+		logMessage(WARN, component, message, fields)
+	}
+}
+
+// ErrorCF logs like the package ErrorCF, subject to sampling by key.
+func (s *SampledLogger) ErrorCF(key, component, message string, fields map[string]any) {
+	if fields, ok := s.sample(key, fields); ok {
+		logMessage(ERROR, component, message, fields)
+	}
+}
+
+// Reset forgets the count for key, so the next occurrence is logged again.
+func (s *SampledLogger) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.counts, key)
+}
+
+// sample counts an occurrence of key and reports whether to log it, with the
+// fields to use.
+func (s *SampledLogger) sample(key string, fields map[string]any) (map[string]any, bool) {
+	s.mu.Lock()
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	s.mu.Unlock()
+
+	if n <= s.first {
+		return fields, true
+	}
+	if s.every <= 0 || n%s.every != 0 {
+		return nil, false
+	}
+	sampled := make(map[string]any, len(fields)+1)
+	maps.Copy(sampled, fields)
+	sampled["occurrences"] = n
+	return sampled, true
+}
+
+// SampledWarnCF is WarnCF for messages that may repeat many times in a row.
+// It logs the first 3 occurrences of key and then every 100th, until
+// ResetSampled(key).
+func SampledWarnCF(key, component, message string, fields map[string]any) {
+	if fields, ok := defaultSampler.sample(key, fields); ok {
+		logMessage(WARN, component, message, fields)
+	}
+}
+
+// SampledErrorCF is ErrorCF sampled by key, as SampledWarnCF, for failures
+// that stay errors however often they repeat.
+func SampledErrorCF(key, component, message string, fields map[string]any) {
+	if fields, ok := defaultSampler.sample(key, fields); ok {
+		logMessage(ERROR, component, message, fields)
+	}
+}
+
+// ResetSampled marks the condition behind key as cleared.
+func ResetSampled(key string) {
+	defaultSampler.Reset(key)
+}
+
+// SampledWarnF is WarnF sampled by key, as SampledWarnCF.
+func (l *Logger) SampledWarnF(key, message string, fields map[string]any) {
+	if fields, ok := defaultSampler.sample(key, l.merge(fields)); ok {
+		logMessage(WARN, l.component, message, fields)
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSampledWarnLogsFirstThreeThenEveryHundredth(t *testing.T) {
+	buf := captureStderr(t)
+	s := NewSampledLogger(3, 100)
+
+	for range 200 {
+		s.WarnCF("poll", "twicas", "poll error", map[string]any{"error": "timeout"})
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5:\n%s", len(lines), buf.String())
+	}
+	for i, line := range lines[:3] {
+		if strings.Contains(line, "occurrences=") {
+			t.Errorf("line %d should not be marked as sampled: %s", i, line)
+		}
+	}
+	for i, want := range map[int]string{3: "occurrences=100", 4: "occurrences=200"} {
+		if !strings.Contains(lines[i], want) || !strings.Contains(lines[i], "error=timeout") {
+			t.Errorf("line %d = %q, want %s", i, lines[i], want)
+		}
+	}
+}
+
+func TestSampledWarnResetAndKeys(t *testing.T) {
+	buf := captureStderr(t)
+	s := NewSampledLogger(1, 100)
+
+	s.WarnCF("a", "test", "a failed", nil)
+	s.WarnCF("a", "test", "a failed", nil) // suppressed
+	s.WarnCF("b", "test", "b failed", nil) // separate key
+	s.Reset("a")
+	s.WarnCF("a", "test", "a failed again", nil)
+
+	got := strings.Count(buf.String(), "\n")
+	if got != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", got, buf.String())
+	}
+	if !strings.Contains(buf.String(), "a failed again") {
+		t.Errorf("occurrence after Reset was not logged:\n%s", buf.String())
+	}
+}
+
+func TestSampledErrorKeepsErrorLevel(t *testing.T) {
+	buf := captureStderr(t)
+	s := NewSampledLogger(1, 2)
+
+	for range 2 {
+		s.ErrorCF("reconnect", "onebot", "Reconnect failed", nil)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, "[ERROR]") {
+			t.Errorf("line %d not logged at ERROR: %s", i, line)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package logger
+
+import "maps"
+
+// Logger writes log lines that carry a fixed component and set of fields,
+// so callers logging about the same thing need not repeat them. Fields passed
+// to an individual call are added to the bound ones and win on conflict.
+type Logger struct {
+	component string
+	fields    map[string]any
+}
+
+// With returns a Logger with fields bound to every line it writes.
+func With(fields map[string]any) *Logger {
+	return &Logger{fields: maps.Clone(fields)}
+}
+
+// WithC is like With but also binds a component, as passed to InfoCF.
+func WithC(component string, fields map[string]any) *Logger {
+	return &Logger{component: component, fields: maps.Clone(fields)}
+}
+
+// With returns a copy of l with fields bound in addition to l's own.
+func (l *Logger) With(fields map[string]any) *Logger {
+	return &Logger{component: l.component, fields: l.merge(fields)}
+}
+
+func (l *Logger) merge(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return l.fields
+	}
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	maps.Copy(merged, l.fields)
+	maps.Copy(merged, fields)
+	return merged
+}
+
+func (l *Logger) Debug(message string) {
+	logMessage(DEBUG, l.component, message, l.fields)
+}
+
+func (l *Logger) DebugF(message string, fields map[string]any) {
+	logMessage(DEBUG, l.component, message, l.merge(fields))
+}
+
+func (l *Logger) Info(message string) {
+	logMessage(INFO, l.component, message, l.fields)
+}
+
+func (l *Logger) InfoF(message string, fields map[string]any) {
+	logMessage(INFO, l.component, message, l.merge(fields))
+}
+
+func (l *Logger) Warn(message string) {
+	logMessage(WARN, l.component, message, l.fields)
+}
+
+func (l *Logger) WarnF(message string, fields map[string]any) {
+	logMessage(WARN, l.component, message, l.merge(fields))
+}
+
+func (l *Logger) Error(message string) {
+	logMessage(ERROR, l.component, message, l.fields)
+}
+
+func (l *Logger) ErrorF(message string, fields map[string]any) {
+	logMessage(ERROR, l.component, message, l.merge(fields))
+}
+
+func (l *Logger) Fatal(message string) {
+	logMessage(FATAL, l.component, message, l.fields)
+}
+
+func (l *Logger) FatalF(message string, fields map[string]any) {
+	logMessage(FATAL, l.component, message, l.merge(fields))
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStderr redirects the standard logger for the duration of the test.
+func captureStderr(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestWithBindsFieldsToEveryLine(t *testing.T) {
+	initialLevel := GetLevel()
+	defer SetLevel(initialLevel)
+	SetLevel(DEBUG)
+	buf := captureStderr(t)
+
+	l := WithC("twicas", map[string]any{"channel": "twicas", "movie_id": "m1"})
+	l.Debug("polling")
+	l.InfoF("received", map[string]any{"user_id": "u1"})
+	l.WarnF("override", map[string]any{"movie_id": "m2"})
+	l.With(map[string]any{"attempt": 2}).Error("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, " twicas: ") || !strings.Contains(line, "channel=twicas") {
+			t.Errorf("line missing bound component or fields: %s", line)
+		}
+	}
+	for i, want := range []string{"movie_id=m1", "user_id=u1", "movie_id=m2", "attempt=2"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], want)
+		}
+	}
+	if strings.Contains(lines[2], "movie_id=m1") {
+		t.Errorf("per-call field should replace the bound one: %s", lines[2])
+	}
+}
+
+func TestWithDoesNotShareFields(t *testing.T) {
+	fields := map[string]any{"a": 1}
+	parent := With(fields)
+	fields["a"] = 2
+	child := parent.With(map[string]any{"b": 3})
+
+	if parent.fields["a"] != 1 {
+		t.Errorf("With kept a reference to the caller's map: %v", parent.fields)
+	}
+	if _, ok := parent.fields["b"]; ok {
+		t.Errorf("child fields leaked into parent: %v", parent.fields)
+	}
+	if child.fields["a"] != 1 || child.fields["b"] != 3 {
+		t.Errorf("child fields = %v", child.fields)
+	}
+}
+
+func TestWithReportsCallerOfMethod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := EnableFileLogging(path); err != nil {
+		t.Fatal(err)
+	}
+	captureStderr(t)
+	With(map[string]any{"k": "v"}).Info("hello")
+	DisableFileLogging()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		t.Fatal("no entry written")
+	}
+	var entry LogEntry
+	if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Fields["k"] != "v" {
+		t.Errorf("Fields = %v", entry.Fields)
+	}
+	if !strings.Contains(entry.Caller, "with_test.go") {
+		t.Errorf("Caller = %q, want the test file", entry.Caller)
+	}
+}
@@ -0,0 +1,87 @@
+// Package plugin loads channel extensions built as Go plugins
+// (go build -buildmode=plugin). Plugins must be built with the same Go
+// version and module versions as picoclaw itself, and are only supported
+// where the standard library plugin package is (Linux, macOS and FreeBSD,
+// with cgo enabled). Release binaries are built with CGO_ENABLED=0 and cannot
+// load plugins; see Supported.
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	goplugin "plugin"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// RegisterSymbol is the function every plugin must export:
+//
+//	func Register(reg *plugin.Registry)
+const RegisterSymbol = "Register"
+
+// ErrUnsupported is returned by Load when the binary cannot open Go plugins.
+var ErrUnsupported = errors.New("this build cannot load Go plugins (needs cgo on Linux, macOS or FreeBSD)")
+
+// PluginLoader opens every .so file in a directory and lets it register hooks.
+type PluginLoader struct {
+	dir string
+}
+
+func NewPluginLoader(dir string) *PluginLoader {
+	return &PluginLoader{dir: dir}
+}
+
+// Load opens the plugins in name order and calls their Register function with
+// reg. A plugin that fails to load is skipped; its error is included in the
+// returned error alongside the names of the plugins that did load. Without
+// plugin support it returns ErrUnsupported and loads nothing.
+func (l *PluginLoader) Load(reg *Registry) ([]string, error) {
+	if !Supported {
+		return nil, ErrUnsupported
+	}
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin dir: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".so") {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+
+	var loaded []string
+	var errs []error
+	for _, name := range names {
+		if err := loadPlugin(filepath.Join(l.dir, name), reg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		loaded = append(loaded, name)
+		logger.InfoCF("plugin", "Plugin loaded", map[string]any{"plugin": name})
+	}
+	return loaded, errors.Join(errs...)
+}
+
+func loadPlugin(path string, reg *Registry) error {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup(RegisterSymbol)
+	if err != nil {
+		return err
+	}
+	register, ok := sym.(func(*Registry))
+	if !ok {
+		return fmt.Errorf("%s has type %T, want func(*plugin.Registry)", RegisterSymbol, sym)
+	}
+	register(reg)
+	return nil
+}
@@ -0,0 +1,121 @@
+package plugin_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/plugin"
+)
+
+// These tests live in package plugin_test: the plugin links against the
+// regular build of pkg/plugin, which an in-package test binary would not match.
+
+// testPlugin holds the result of building and loading testdata/shout. A Go
+// plugin can only be opened once per process, so the fixture is shared by
+// every run of the tests that need it (e.g. under -count=2).
+var testPlugin struct {
+	once   sync.Once
+	skip   string
+	reg    *plugin.Registry
+	loaded []string
+	err    error
+}
+
+// loadTestPlugin compiles testdata/shout as a Go plugin and loads it into a
+// registry, once per test binary.
+func loadTestPlugin(t *testing.T) (*plugin.Registry, []string, error) {
+	t.Helper()
+	if !plugin.Supported {
+		t.Skipf("Go plugins are not supported on %s without cgo", runtime.GOOS)
+	}
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+
+	testPlugin.once.Do(func() {
+		dir, err := os.MkdirTemp("", "picoclaw-plugin-test")
+		if err != nil {
+			testPlugin.skip = fmt.Sprintf("cannot create plugin dir: %v", err)
+			return
+		}
+		// The loaded plugin stays mapped after its file is removed.
+		defer os.RemoveAll(dir)
+
+		args := []string{"build", "-buildmode=plugin"}
+		if raceEnabled {
+			// The plugin must match the host binary's instrumentation.
+			args = append(args, "-race")
+		}
+		args = append(args, "-o", filepath.Join(dir, "shout.so"), "./testdata/shout")
+
+		cmd := exec.Command("go", args...)
+		cmd.Env = append(os.Environ(), "CGO_ENABLED=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			testPlugin.skip = fmt.Sprintf("cannot build test plugin: %v\n%s", err, out)
+			return
+		}
+		// Files without the .so suffix are ignored.
+		os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not a plugin"), 0o644)
+
+		testPlugin.reg = plugin.NewRegistry()
+		testPlugin.loaded, testPlugin.err = plugin.NewPluginLoader(dir).Load(testPlugin.reg)
+	})
+	if testPlugin.skip != "" {
+		t.Skip(testPlugin.skip)
+	}
+	return testPlugin.reg, testPlugin.loaded, testPlugin.err
+}
+
+func TestPluginLoaderInvokesHooks(t *testing.T) {
+	reg, loaded, err := loadTestPlugin(t)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0] != "shout.so" {
+		t.Fatalf("loaded = %v, want [shout.so]", loaded)
+	}
+
+	if reg.Allow("buy spam now") {
+		t.Error("plugin pre-filter should reject spam")
+	}
+	if got := reg.Format("alice", "hello"); got != "alice: HELLO" {
+		t.Errorf("Format = %q, want %q", got, "alice: HELLO")
+	}
+}
+
+func TestPluginLoaderReportsBadPlugin(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "broken.so"), []byte("not an ELF file"), 0o644)
+
+	loaded, err := plugin.NewPluginLoader(dir).Load(plugin.NewRegistry())
+	if err == nil {
+		t.Fatal("expected an error for a corrupt plugin")
+	}
+	if len(loaded) != 0 {
+		t.Errorf("loaded = %v, want none", loaded)
+	}
+}
+
+func TestPluginLoaderUnsupportedBuild(t *testing.T) {
+	if plugin.Supported {
+		t.Skip("this build can load Go plugins")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "shout.so"), []byte("not an ELF file"), 0o644)
+
+	if _, err := plugin.NewPluginLoader(dir).Load(plugin.NewRegistry()); !errors.Is(err, plugin.ErrUnsupported) {
+		t.Errorf("Load = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestPluginLoaderMissingDir(t *testing.T) {
+	if _, err := plugin.NewPluginLoader(filepath.Join(t.TempDir(), "nope")).Load(plugin.NewRegistry()); err == nil {
+		t.Error("expected an error for a missing plugin dir")
+	}
+}
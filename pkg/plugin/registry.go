@@ -0,0 +1,62 @@
+package plugin
+
+import "sync"
+
+// Registry collects the hooks contributed by plugins. Channels consult it for
+// every inbound message: pre-filters decide whether the message is kept and
+// formatters rewrite its text, each in registration order.
+//
+// A nil *Registry is valid and has no hooks.
+type Registry struct {
+	mu         sync.RWMutex
+	preFilters []func(text string) bool
+	formatters []func(author, text string) string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// AddPreFilter registers fn. A message is dropped as soon as one pre-filter
+// returns false.
+func (r *Registry) AddPreFilter(fn func(text string) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preFilters = append(r.preFilters, fn)
+}
+
+// AddFormatter registers fn. Each formatter receives the output of the one
+// registered before it.
+func (r *Registry) AddFormatter(fn func(author, text string) string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.formatters = append(r.formatters, fn)
+}
+
+// Allow reports whether every pre-filter accepts text.
+func (r *Registry) Allow(text string) bool {
+	if r == nil {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, fn := range r.preFilters {
+		if !fn(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// Format runs text through the formatters.
+func (r *Registry) Format(author, text string) string {
+	if r == nil {
+		return text
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, fn := range r.formatters {
+		text = fn(author, text)
+	}
+	return text
+}
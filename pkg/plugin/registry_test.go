@@ -0,0 +1,38 @@
+package plugin_test
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/plugin"
+)
+
+func TestRegistryHooksRunInOrder(t *testing.T) {
+	reg := plugin.NewRegistry()
+	reg.AddPreFilter(func(text string) bool { return text != "drop-me" })
+	reg.AddPreFilter(func(text string) bool { return len(text) < 20 })
+	reg.AddFormatter(func(author, text string) string { return text + "!" })
+	reg.AddFormatter(func(author, text string) string { return author + ": " + text })
+
+	if reg.Allow("drop-me") {
+		t.Error("first pre-filter should reject")
+	}
+	if reg.Allow("this message is far too long") {
+		t.Error("second pre-filter should reject")
+	}
+	if !reg.Allow("hello") {
+		t.Error("hello should pass both pre-filters")
+	}
+	if got := reg.Format("alice", "hi"); got != "alice: hi!" {
+		t.Errorf("Format = %q, want %q", got, "alice: hi!")
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var reg *plugin.Registry
+	if !reg.Allow("anything") {
+		t.Error("nil registry should allow everything")
+	}
+	if got := reg.Format("a", "text"); got != "text" {
+		t.Errorf("nil registry Format = %q", got)
+	}
+}
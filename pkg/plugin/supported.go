@@ -0,0 +1,6 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+package plugin
+
+// Supported reports whether this binary can open Go plugins.
+const Supported = true
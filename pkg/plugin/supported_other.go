@@ -0,0 +1,7 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+package plugin
+
+// Supported reports whether this binary can open Go plugins. Plugins need
+// cgo, which the release builds disable.
+const Supported = false
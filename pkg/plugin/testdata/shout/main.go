@@ -0,0 +1,20 @@
+// Command shout is a test plugin: it drops messages containing "spam" and
+// prefixes the rest with the author's name.
+package main
+
+import (
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/plugin"
+)
+
+func Register(reg *plugin.Registry) {
+	reg.AddPreFilter(func(text string) bool {
+		return !strings.Contains(text, "spam")
+	})
+	reg.AddFormatter(func(author, text string) string {
+		return author + ": " + strings.ToUpper(text)
+	})
+}
+
+func main() {}
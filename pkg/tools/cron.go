@@ -21,7 +21,7 @@ type JobExecutor interface {
 type CronTool struct {
 	cronService *cron.CronService
 	executor    JobExecutor
-	msgBus      *bus.MessageBus
+	msgBus      bus.Publisher
 	execTool    *ExecTool
 	channel     string
 	chatID      string
@@ -31,7 +31,7 @@ type CronTool struct {
 // NewCronTool creates a new CronTool
 // execTimeout: 0 means no timeout, >0 sets the timeout duration
 func NewCronTool(
-	cronService *cron.CronService, executor JobExecutor, msgBus *bus.MessageBus, workspace string, restrict bool,
+	cronService *cron.CronService, executor JobExecutor, msgBus bus.Publisher, workspace string, restrict bool,
 	execTimeout time.Duration, config *config.Config,
 ) *CronTool {
 	execTool := NewExecToolWithConfig(workspace, restrict, config)
@@ -294,7 +294,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			output = fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM)
 		}
 
-		t.msgBus.PublishOutbound(bus.OutboundMessage{
+		t.msgBus.PublishOutbound(ctx, bus.OutboundMessage{
 			Channel: channel,
 			ChatID:  chatID,
 			Content: output,
@@ -304,7 +304,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 
 	// If deliver=true, send message directly without agent processing
 	if job.Payload.Deliver {
-		t.msgBus.PublishOutbound(bus.OutboundMessage{
+		t.msgBus.PublishOutbound(ctx, bus.OutboundMessage{
 			Channel: channel,
 			ChatID:  chatID,
 			Content: job.Payload.Message,